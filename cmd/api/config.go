@@ -0,0 +1,26 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"pt-brm/internal/config"
+	"text/tabwriter"
+)
+
+const configUsage = "uso: config print"
+
+// runConfig ejecuta el subcomando config con los argumentos recibidos.
+func runConfig(cfg *config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		return errors.New(configUsage)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CLAVE\tVALOR\tORIGEN\tENTORNO\tFLAG")
+	for _, setting := range cfg.Settings() {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t-%s\n", setting.Key, setting.Value, setting.Source, setting.Env, setting.Flag)
+	}
+
+	return w.Flush()
+}
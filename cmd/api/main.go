@@ -2,51 +2,90 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
+	"pt-brm/internal/auth"
 	"pt-brm/internal/config"
 	"pt-brm/internal/database"
+	"pt-brm/internal/jobs"
+	"pt-brm/internal/logging"
+	"pt-brm/internal/repositories"
 	"pt-brm/internal/routes"
+	"pt-brm/internal/services"
 )
 
 func main() {
-	// Cargar la configuración
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		log.Fatalf("no se pudo cargar la confuguracion de la base de datos: %v", err)
+	// Cargar la configuración: archivo, entorno y flags (main [flags] [subcomando])
+	cfg, args, err := config.LoadConfig(os.Args[1:])
+	if config.IsHelp(err) {
+		return
+	}
+
+	// Subcomando: main config print (muestra la configuración aunque sea inválida)
+	if len(args) > 0 && args[0] == "config" && cfg != nil {
+		if printErr := runConfig(cfg, args[1:]); printErr != nil {
+			log.Fatalf("Error en config: %v", printErr)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
 	}
 
-	// Conectar a la base de datos
-	db, err := database.NewConnection(cfg.Database)
 	if err != nil {
-		log.Fatalf("Error connecting to database: %v", err)
+		log.Fatalf("no se pudo cargar la confuguracion: %v", err)
 	}
-	defer db.Close()
+	logging.Setup(cfg.LogLevel)
 
-	log.Println("✅ Conexión a la base de datos exitosa")
+	// Contexto base de las solicitudes HTTP: se cancela si el apagado no
+	// termina a tiempo, para abortar las consultas que sigan en curso
+	requestsCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
 
-	// Ejecutar migraciones
-	if err := db.Migrate(); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
+	// Subcomando: main migrate up|down|status|to N
+	if len(args) > 0 && args[0] == "migrate" {
+		db, err := database.Connect(requestsCtx, cfg.Database)
+		if err != nil {
+			log.Fatalf("Error connecting to database: %v", err)
+		}
+		defer db.Close()
+
+		if err := runMigrate(requestsCtx, db, args[1:]); err != nil {
+			log.Fatalf("Error en migrate: %v", err)
+		}
+		return
+	}
+
+	// Claves y listas de la autenticación; un error aquí no se resuelve reintentando
+	security, err := newSecurity(cfg)
+	if err != nil {
+		log.Fatalf("Error en la autenticación: %v", err)
 	}
 
-	// Crear el router
-	router := routes.NewRouter(db)
-	// Configurar las rutas
-	httpHandler := router.SetupRoutes()
+	// Jobs y recarga de configuración, se detienen al apagar el servidor
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
 
-	// Iniciar el servidor HTTP
+	// El servidor empieza a escuchar antes de tener la base de datos: mientras
+	// arranca, /ready responde 503 y /live 200
+	gate := routes.NewStartupGate()
 	srv := &http.Server{
-		Addr:         ":" + cfg.Server.Port,
-		Handler:      httpHandler,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		Addr:              ":" + cfg.Server.Port,
+		Handler:           gate,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+		BaseContext: func(net.Listener) context.Context {
+			return requestsCtx
+		},
 	}
 
 	go func() {
@@ -57,19 +96,110 @@ func main() {
 		}
 	}()
 
-	// Manejar señales de terminación
+	// Registrar las señales antes de arrancar: un SIGHUP sin manejador
+	// terminaría el proceso mientras se conecta a la base de datos
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
 
+	// Conectar a la base de datos, migrar y habilitar las rutas; se cancela si
+	// llega una señal de apagado antes de terminar
+	startCtx, cancelStart := context.WithCancel(requestsCtx)
+	defer cancelStart()
+	started := make(chan *database.DB, 1)
+	go func() {
+		defer close(started)
+		db, err := startAPI(startCtx, jobsCtx, cfg, security, gate, hup)
+		if err != nil {
+			if startCtx.Err() != nil {
+				return
+			}
+			log.Fatalf("No se pudo iniciar la API: %v", err)
+		}
+		started <- db
+	}()
+
+	// Manejar señales de terminación
 	sig := <-sigChan
 	log.Printf("señal recibida %s, apagando el servidor...", sig)
+	cancelStart()
+	stopJobs()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
+		// Cancelar las consultas de las solicitudes que no terminaron a tiempo
+		cancelRequests()
+		srv.Close()
 		log.Fatalf("No se pudo apagar el servidor: %v", err)
 	}
 
+	if db, ok := <-started; ok {
+		db.Close()
+	}
+
 	log.Println("Servidor apagado correctamente")
 }
+
+// startAPI conecta con la base de datos y aplica las migraciones (con
+// reintentos), arranca los jobs y la recarga de configuración (con los SIGHUP
+// de hup), y habilita las rutas en gate.
+func startAPI(ctx, jobsCtx context.Context, cfg *config.Config, security routes.Security, gate *routes.StartupGate, hup <-chan os.Signal) (*database.DB, error) {
+	db, err := database.Connect(ctx, cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+	log.Println("✅ Conexión a la base de datos exitosa")
+
+	// Ejecutar migraciones
+	if err := db.MigrateWithRetry(ctx, cfg.Database); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	// Job de purga de usuarios eliminados
+	userService := services.NewUserService(repositories.NewUserRepository(db), db)
+	go jobs.NewPurgeJob(userService, cfg.Purge).Run(jobsCtx)
+
+	// Recarga de la configuración con SIGHUP o al cambiar el archivo de configuración
+	watcher := config.NewWatcher(cfg, os.Args[1:])
+	watcher.Subscribe(logging.Observer{})
+	watcher.Subscribe(db)
+	go watcher.Watch(jobsCtx, hup)
+
+	// Crear el router y configurar las rutas
+	router := routes.NewRouter(db, watcher, security)
+	gate.SetReady(router.SetupRoutes())
+	log.Println("✅ API lista para recibir solicitudes")
+
+	return db, nil
+}
+
+// newSecurity carga las claves de los tokens y la política de contraseñas.
+func newSecurity(cfg *config.Config) (routes.Security, error) {
+	security := routes.Security{Hasher: auth.NewPasswordHasher(cfg.Password)}
+
+	var err error
+	if cfg.Auth.Enabled {
+		if security.Verifier, err = auth.NewVerifier(cfg.Auth); err != nil {
+			return security, err
+		}
+	} else {
+		log.Println("Warning: autenticación deshabilitada (AUTH_ENABLED=false), /api/v1 es público")
+	}
+
+	if security.Issuer, err = auth.NewIssuer(cfg.Auth); err != nil {
+		return security, err
+	}
+	if security.Issuer == nil {
+		log.Println("Warning: sin AUTH_SIGNING_KEY_FILE ni AUTH_HS256_SECRET, POST /api/v1/auth/login no está disponible")
+	}
+
+	if security.Policy, err = auth.NewPasswordPolicy(cfg.Password); err != nil {
+		return security, err
+	}
+	return security, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"pt-brm/internal/database"
+	"strconv"
+	"text/tabwriter"
+)
+
+const migrateUsage = "uso: migrate up|down|status|to N"
+
+// runMigrate ejecuta el subcomando migrate con los argumentos recibidos.
+func runMigrate(ctx context.Context, db *database.DB, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		return migrator.Up(ctx)
+	case "down":
+		return migrator.Down(ctx)
+	case "to":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil || version < 0 {
+			return fmt.Errorf("versión inválida: %s", args[1])
+		}
+		return migrator.To(ctx, version)
+	case "status":
+		return printMigrationStatus(ctx, migrator)
+	default:
+		return errors.New(migrateUsage)
+	}
+}
+
+func printMigrationStatus(ctx context.Context, migrator *database.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNOMBRE\tESTADO\tAPLICADA")
+	for _, status := range statuses {
+		state, appliedAt := "pendiente", "-"
+		if status.Applied {
+			state = "aplicada"
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			if status.Modified {
+				state = "modificada"
+			}
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// Las API keys tienen el formato ptb_<id>.<secreto>: "ptb_<id>" es el prefijo
+// que se guarda en claro para buscar la clave y el secreto solo se guarda como
+// SHA-256 con sal. Como el secreto tiene 256 bits aleatorios, un hash rápido
+// es suficiente (no hace falta un KDF lento como con las contraseñas).
+const (
+	apiKeyPrefix      = "ptb_"
+	apiKeyIDBytes     = 5
+	apiKeySecretBytes = 32
+	apiKeySaltBytes   = 16
+)
+
+var apiKeyIDEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// APIKeySecret es una API key recién generada.
+type APIKeySecret struct {
+	// Key es la clave completa que se entrega al cliente una sola vez.
+	Key    string
+	Prefix string
+	Salt   string
+	Hash   string
+}
+
+// NewAPIKeySecret genera una API key aleatoria con su prefijo y hash.
+func NewAPIKeySecret() (*APIKeySecret, error) {
+	id := make([]byte, apiKeyIDBytes)
+	secret := make([]byte, apiKeySecretBytes)
+	salt := make([]byte, apiKeySaltBytes)
+	for _, b := range [][]byte{id, secret, salt} {
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+	}
+
+	prefix := apiKeyPrefix + strings.ToLower(apiKeyIDEncoding.EncodeToString(id))
+	encodedSecret := base64.RawURLEncoding.EncodeToString(secret)
+	encodedSalt := hex.EncodeToString(salt)
+	return &APIKeySecret{
+		Key:    prefix + "." + encodedSecret,
+		Prefix: prefix,
+		Salt:   encodedSalt,
+		Hash:   hashAPIKeySecret(encodedSalt, encodedSecret),
+	}, nil
+}
+
+// SplitAPIKey separa una API key en su prefijo y su secreto.
+func SplitAPIKey(key string) (prefix, secret string, ok bool) {
+	prefix, secret, ok = strings.Cut(key, ".")
+	if !ok || !strings.HasPrefix(prefix, apiKeyPrefix) || secret == "" {
+		return "", "", false
+	}
+	return prefix, secret, true
+}
+
+// CheckAPIKeySecret compara en tiempo constante el secreto con el hash guardado.
+func CheckAPIKeySecret(secret, salt, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashAPIKeySecret(salt, secret)), []byte(hash)) == 1
+}
+
+func hashAPIKeySecret(salt, secret string) string {
+	sum := sha256.Sum256([]byte(salt + secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAPIKeySecret(t *testing.T) {
+	generated, err := NewAPIKeySecret()
+	if err != nil {
+		t.Fatalf("NewAPIKeySecret: %v", err)
+	}
+	other, err := NewAPIKeySecret()
+	if err != nil {
+		t.Fatalf("NewAPIKeySecret: %v", err)
+	}
+	if generated.Key == other.Key || generated.Salt == other.Salt {
+		t.Fatal("dos claves generadas son iguales")
+	}
+
+	prefix, secret, ok := SplitAPIKey(generated.Key)
+	if !ok || prefix != generated.Prefix {
+		t.Fatalf("SplitAPIKey(%q) = %q, %v", generated.Key, prefix, ok)
+	}
+
+	tests := []struct {
+		name   string
+		secret string
+		salt   string
+		hash   string
+		want   bool
+	}{
+		{name: "correcto", secret: secret, salt: generated.Salt, hash: generated.Hash, want: true},
+		{name: "secreto de otra clave", secret: strings.SplitN(other.Key, ".", 2)[1], salt: generated.Salt, hash: generated.Hash},
+		{name: "secreto modificado", secret: secret + "x", salt: generated.Salt, hash: generated.Hash},
+		{name: "sal de otra clave", secret: secret, salt: other.Salt, hash: generated.Hash},
+		{name: "hash vacío", secret: secret, salt: generated.Salt, hash: ""},
+		{name: "hash en mayúsculas", secret: secret, salt: generated.Salt, hash: strings.ToUpper(generated.Hash)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CheckAPIKeySecret(tt.secret, tt.salt, tt.hash); got != tt.want {
+				t.Errorf("CheckAPIKeySecret = %v, se esperaba %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitAPIKey(t *testing.T) {
+	tests := []struct {
+		key        string
+		wantPrefix string
+		wantSecret string
+		wantOK     bool
+	}{
+		{key: "ptb_abcdefgh.c2VjcmV0", wantPrefix: "ptb_abcdefgh", wantSecret: "c2VjcmV0", wantOK: true},
+		{key: "ptb_abcdefgh.a.b", wantPrefix: "ptb_abcdefgh", wantSecret: "a.b", wantOK: true},
+		{key: "ptb_abcdefgh", wantOK: false},
+		{key: "ptb_abcdefgh.", wantOK: false},
+		{key: "xyz_abcdefgh.c2VjcmV0", wantOK: false},
+		{key: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			prefix, secret, ok := SplitAPIKey(tt.key)
+			if prefix != tt.wantPrefix || secret != tt.wantSecret || ok != tt.wantOK {
+				t.Errorf("SplitAPIKey(%q) = %q, %q, %v", tt.key, prefix, secret, ok)
+			}
+		})
+	}
+}
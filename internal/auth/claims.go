@@ -0,0 +1,53 @@
+// Package auth valida los tokens JWT de las solicitudes a la API y guarda
+// los datos del usuario autenticado en el contexto.
+package auth
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Claims son los datos de un token autenticado.
+type Claims struct {
+	jwt.RegisteredClaims
+	// Scope son los permisos del token separados por espacios (RFC 8693), por ejemplo "users:read users:write".
+	Scope string `json:"scope,omitempty"`
+	// APIKeyID es la API key con la que se autenticó la solicitud; 0 si vino un token.
+	APIKeyID int `json:"-"`
+}
+
+// NewAPIKeyClaims crea los claims de una solicitud autenticada con API key.
+func NewAPIKeyClaims(id int, scopes []string) *Claims {
+	return &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: "api_key:" + strconv.Itoa(id)},
+		Scope:            strings.Join(scopes, " "),
+		APIKeyID:         id,
+	}
+}
+
+// Scopes devuelve los permisos del token.
+func (c *Claims) Scopes() []string {
+	return strings.Fields(c.Scope)
+}
+
+// HasScope indica si el token tiene el permiso indicado.
+func (c *Claims) HasScope(scope string) bool {
+	return slices.Contains(c.Scopes(), scope)
+}
+
+type claimsKey struct{}
+
+// WithClaims guarda en ctx los datos del token autenticado.
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// ClaimsFromContext devuelve los datos del token autenticado, si hay.
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*Claims)
+	return claims, ok
+}
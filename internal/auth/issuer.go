@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"pt-brm/internal/config"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Issuer firma los tokens que se entregan al iniciar sesión, con los mismos
+// iss y aud que exige el Verifier.
+type Issuer struct {
+	method   jwt.SigningMethod
+	key      any
+	kid      string
+	issuer   string
+	audience string
+	ttl      time.Duration
+	scopes   string
+}
+
+// NewIssuer crea el Issuer con la clave privada de cfg.SigningKeyFile o, si no
+// hay, con cfg.HS256Secret. Devuelve nil si no hay con qué firmar.
+func NewIssuer(cfg config.AuthConfig) (*Issuer, error) {
+	i := &Issuer{
+		kid:      cfg.SigningKeyID,
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+		ttl:      cfg.TokenTTL,
+		scopes:   strings.Join(cfg.LoginScopes, " "),
+	}
+
+	switch {
+	case cfg.SigningKeyFile != "":
+		key, err := readPrivateKeyFile(cfg.SigningKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		i.key = key
+		if _, ok := key.(*rsa.PrivateKey); ok {
+			i.method = jwt.SigningMethodRS256
+		} else {
+			i.method = jwt.SigningMethodEdDSA
+		}
+	case cfg.HS256Secret != "":
+		i.method = jwt.SigningMethodHS256
+		i.key = []byte(cfg.HS256Secret)
+	default:
+		return nil, nil
+	}
+
+	return i, nil
+}
+
+// Issue firma un token para subject. Devuelve el token y su expiración.
+func (i *Issuer) Issue(subject string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(i.ttl)
+
+	claims := &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
+			Issuer:    i.issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		Scope: i.scopes,
+	}
+	if i.audience != "" {
+		claims.Audience = jwt.ClaimStrings{i.audience}
+	}
+
+	token := jwt.NewWithClaims(i.method, claims)
+	if i.kid != "" {
+		token.Header["kid"] = i.kid
+	}
+	signed, err := token.SignedString(i.key)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("no se pudo firmar el token: %w", err)
+	}
+	return signed, expiresAt, nil
+}
+
+// readPrivateKeyFile lee una clave privada RSA o Ed25519 en formato PEM
+// (PKCS#8, o PKCS#1 para RSA).
+func readPrivateKeyFile(filename string) (crypto.Signer, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer la clave privada: %w", err)
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("%s no contiene una clave PEM", filename)
+	}
+
+	var key any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("clave privada inválida en %s: %w", filename, err)
+	}
+
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return key, nil
+	case ed25519.PrivateKey:
+		return key, nil
+	}
+	return nil, fmt.Errorf("%s: solo se admiten claves RSA o Ed25519", filename)
+}
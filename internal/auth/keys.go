@@ -0,0 +1,304 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"pt-brm/internal/config"
+	"sync"
+	"time"
+)
+
+// Algoritmos de firma aceptados.
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// jwksForcedRefresh es la espera mínima entre relecturas del JWKS provocadas
+// por un kid desconocido, para que tokens con kid inventados no fuercen
+// lecturas constantes del archivo.
+const jwksForcedRefresh = 5 * time.Second
+
+// verificationKey es una clave para verificar firmas: []byte (HS256),
+// *rsa.PublicKey (RS256) o ed25519.PublicKey (EdDSA).
+type verificationKey struct {
+	kid string
+	alg string
+	key any
+}
+
+// KeySet reúne las claves de verificación configuradas. Las del archivo JWKS
+// se vuelven a leer cuando el archivo cambia, para poder rotarlas sin reiniciar.
+type KeySet struct {
+	static   []verificationKey
+	jwksFile string
+	refresh  time.Duration
+
+	mu        sync.Mutex
+	jwks      []verificationKey
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+// NewKeySet carga las claves de la configuración. Falla si alguna no se puede leer.
+func NewKeySet(cfg config.AuthConfig) (*KeySet, error) {
+	ks := &KeySet{jwksFile: cfg.JWKSFile, refresh: cfg.JWKSRefresh}
+
+	if cfg.HS256Secret != "" {
+		ks.static = append(ks.static, verificationKey{alg: AlgHS256, key: []byte(cfg.HS256Secret)})
+	}
+	if cfg.SigningKeyFile != "" {
+		// Los tokens que firma el Issuer se validan con la clave pública correspondiente
+		signer, err := readPrivateKeyFile(cfg.SigningKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		public := signer.Public()
+		ks.static = append(ks.static, verificationKey{kid: cfg.SigningKeyID, alg: algForKey(public), key: public})
+	}
+	if cfg.PublicKeyFile != "" {
+		key, err := readPublicKeyFile(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		ks.static = append(ks.static, key)
+	}
+	if cfg.JWKSFile != "" {
+		if err := ks.reloadJWKS(time.Now(), true); err != nil {
+			return nil, err
+		}
+	}
+
+	return ks, nil
+}
+
+// lookup busca la clave para un token con el kid y el algoritmo indicados. Sin
+// kid solo se acepta si hay una única clave para ese algoritmo.
+func (ks *KeySet) lookup(kid, alg string) (any, error) {
+	keys := ks.keys(false)
+	if key, ok := findKey(keys, kid, alg); ok {
+		return key, nil
+	}
+
+	// Un kid desconocido puede ser una clave recién rotada
+	if kid != "" && ks.jwksFile != "" {
+		if key, ok := findKey(ks.keys(true), kid, alg); ok {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("no hay una clave %s con kid %q", alg, kid)
+}
+
+func findKey(keys []verificationKey, kid, alg string) (any, bool) {
+	var match []verificationKey
+	for _, k := range keys {
+		if k.alg != alg {
+			continue
+		}
+		if kid != "" && k.kid == kid {
+			return k.key, true
+		}
+		match = append(match, k)
+	}
+	if kid == "" && len(match) == 1 {
+		return match[0].key, true
+	}
+	return nil, false
+}
+
+// keys devuelve las claves vigentes, releyendo el JWKS si toca revisarlo.
+func (ks *KeySet) keys(force bool) []verificationKey {
+	if ks.jwksFile == "" {
+		return ks.static
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	now := time.Now()
+	interval := ks.refresh
+	if force {
+		interval = jwksForcedRefresh
+	}
+	if now.Sub(ks.lastCheck) >= interval {
+		if err := ks.reloadJWKS(now, false); err != nil {
+			// Se siguen usando las claves anteriores
+			log.Printf("Warning: no se pudo recargar %s: %v", ks.jwksFile, err)
+		}
+	}
+
+	return append(ks.jwks[:len(ks.jwks):len(ks.jwks)], ks.static...)
+}
+
+// reloadJWKS vuelve a leer el archivo JWKS si cambió. Se llama con ks.mu tomado
+// (o durante NewKeySet).
+func (ks *KeySet) reloadJWKS(now time.Time, initial bool) error {
+	ks.lastCheck = now
+
+	info, err := os.Stat(ks.jwksFile)
+	if err != nil {
+		return fmt.Errorf("no se pudo leer el JWKS: %w", err)
+	}
+	if !initial && info.ModTime().Equal(ks.modTime) {
+		return nil
+	}
+
+	content, err := os.ReadFile(ks.jwksFile)
+	if err != nil {
+		return fmt.Errorf("no se pudo leer el JWKS: %w", err)
+	}
+	keys, err := parseJWKS(content)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ks.jwksFile, err)
+	}
+
+	if !initial {
+		log.Printf("JWKS recargado: %d claves", len(keys))
+	}
+	ks.jwks = keys
+	ks.modTime = info.ModTime()
+	return nil
+}
+
+// jwk es una clave de un JWKS (RFC 7517); solo se usan los campos de las
+// claves RSA, OKP (Ed25519) y oct (HMAC).
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	K   string `json:"k"`
+}
+
+func parseJWKS(content []byte) ([]verificationKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(content, &set); err != nil {
+		return nil, fmt.Errorf("JWKS inválido: %w", err)
+	}
+
+	var keys []verificationKey
+	for i, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.verificationKey()
+		if err != nil {
+			return nil, fmt.Errorf("clave %d (kid %q): %w", i, k.Kid, err)
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("el JWKS no tiene claves de firma")
+	}
+	return keys, nil
+}
+
+func (k jwk) verificationKey() (verificationKey, error) {
+	key := verificationKey{kid: k.Kid, alg: k.Alg}
+
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return key, fmt.Errorf("n inválido: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return key, fmt.Errorf("e inválido: %w", err)
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+			return key, errors.New("exponente RSA inválido")
+		}
+		key.key = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}
+		key.alg = defaultAlg(key.alg, AlgRS256)
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return key, fmt.Errorf("curva no soportada: %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return key, errors.New("x inválido")
+		}
+		key.key = ed25519.PublicKey(x)
+		key.alg = defaultAlg(key.alg, AlgEdDSA)
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil || len(secret) == 0 {
+			return key, errors.New("k inválido")
+		}
+		key.key = secret
+		key.alg = defaultAlg(key.alg, AlgHS256)
+	default:
+		return key, fmt.Errorf("tipo de clave no soportado: %q", k.Kty)
+	}
+
+	if expected := algForKey(key.key); key.alg != expected {
+		return key, fmt.Errorf("el algoritmo %q no corresponde a una clave %s", key.alg, k.Kty)
+	}
+	return key, nil
+}
+
+func defaultAlg(alg, fallback string) string {
+	if alg == "" {
+		return fallback
+	}
+	return alg
+}
+
+func algForKey(key any) string {
+	switch key.(type) {
+	case []byte:
+		return AlgHS256
+	case *rsa.PublicKey:
+		return AlgRS256
+	case ed25519.PublicKey:
+		return AlgEdDSA
+	}
+	return ""
+}
+
+// readPublicKeyFile lee una clave pública RSA o Ed25519 en formato PEM.
+func readPublicKeyFile(filename string) (verificationKey, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return verificationKey{}, fmt.Errorf("no se pudo leer la clave pública: %w", err)
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return verificationKey{}, fmt.Errorf("%s no contiene una clave PEM", filename)
+	}
+
+	var key any
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	if err != nil {
+		return verificationKey{}, fmt.Errorf("clave pública inválida en %s: %w", filename, err)
+	}
+
+	alg := algForKey(key)
+	if alg == "" {
+		return verificationKey{}, fmt.Errorf("%s: solo se admiten claves RSA o Ed25519", filename)
+	}
+	return verificationKey{alg: alg, key: key}, nil
+}
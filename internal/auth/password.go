@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"pt-brm/internal/config"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+// ErrUnknownHash indica que el hash guardado no tiene un formato reconocido.
+var ErrUnknownHash = errors.New("formato de hash de contraseña desconocido")
+
+// PasswordHasher genera hashes argon2id en formato PHC
+// ($argon2id$v=19$m=...,t=...,p=...$sal$hash) y verifica también los hashes
+// bcrypt heredados. Los hashes con otros parámetros siguen siendo válidos y
+// Verify indica que conviene regenerarlos.
+//
+// Como cada hash reserva la memoria de argon2id, solo se calculan
+// cfg.MaxConcurrent a la vez; el resto espera o desiste si se cancela su contexto.
+type PasswordHasher struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	slots       chan struct{}
+}
+
+func NewPasswordHasher(cfg config.PasswordConfig) *PasswordHasher {
+	return &PasswordHasher{
+		memory:      uint32(cfg.Argon2Memory),
+		iterations:  uint32(cfg.Argon2Iterations),
+		parallelism: uint8(cfg.Argon2Parallelism),
+		slots:       make(chan struct{}, max(cfg.MaxConcurrent, 1)),
+	}
+}
+
+// acquire espera un turno para calcular un hash; la función devuelta lo libera.
+func (h *PasswordHasher) acquire(ctx context.Context) (func(), error) {
+	select {
+	case h.slots <- struct{}{}:
+		return func() { <-h.slots }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("no se pudo calcular el hash de la contraseña: %w", ctx.Err())
+	}
+}
+
+// Hash genera el hash argon2id de la contraseña con una sal aleatoria.
+func (h *PasswordHasher) Hash(ctx context.Context, password string) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("no se pudo generar la sal: %w", err)
+	}
+
+	release, err := h.acquire(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	key := argon2.IDKey([]byte(password), salt, h.iterations, h.memory, h.parallelism, argon2KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.memory, h.iterations, h.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify compara la contraseña con el hash guardado. rehash indica que la
+// contraseña es correcta pero el hash usa bcrypt o parámetros anteriores y
+// debería reemplazarse por uno nuevo.
+func (h *PasswordHasher) Verify(ctx context.Context, password, encoded string) (ok, rehash bool, err error) {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		return h.verifyArgon2id(ctx, password, encoded)
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		release, err := h.acquire(ctx)
+		if err != nil {
+			return false, false, err
+		}
+		defer release()
+
+		err = bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, false, nil
+		}
+		if err != nil {
+			return false, false, fmt.Errorf("hash bcrypt inválido: %w", err)
+		}
+		return true, true, nil
+	}
+	return false, false, ErrUnknownHash
+}
+
+func (h *PasswordHasher) verifyArgon2id(ctx context.Context, password, encoded string) (ok, rehash bool, err error) {
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", sal, hash
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return false, false, ErrUnknownHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, false, fmt.Errorf("versión de argon2 no soportada: %s", parts[2])
+	}
+	var memory, iterations uint32
+	var parallelism uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &parallelism); err != nil {
+		return false, false, fmt.Errorf("parámetros de argon2id inválidos: %w", err)
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, false, fmt.Errorf("sal de argon2id inválida: %w", err)
+	}
+	expected, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(expected) == 0 {
+		return false, false, errors.New("hash de argon2id inválido")
+	}
+
+	release, err := h.acquire(ctx)
+	if err != nil {
+		return false, false, err
+	}
+	key := argon2.IDKey([]byte(password), salt, iterations, memory, parallelism, uint32(len(expected)))
+	release()
+
+	if subtle.ConstantTimeCompare(key, expected) != 1 {
+		return false, false, nil
+	}
+
+	rehash = memory != h.memory || iterations != h.iterations || parallelism != h.parallelism ||
+		len(salt) != argon2SaltLength || len(expected) != argon2KeyLength
+	return true, rehash, nil
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"pt-brm/internal/config"
+	"pt-brm/internal/models"
+	"strings"
+	"unicode/utf8"
+)
+
+// PasswordPolicy valida las contraseñas nuevas: longitud y que no aparezcan
+// en la lista de contraseñas filtradas.
+type PasswordPolicy struct {
+	minLength int
+	maxLength int
+	// breached son los SHA-1 (en hexadecimal, mayúsculas) de las contraseñas filtradas.
+	breached map[string]struct{}
+}
+
+// NewPasswordPolicy crea la política y carga la lista de contraseñas filtradas, si hay.
+func NewPasswordPolicy(cfg config.PasswordConfig) (*PasswordPolicy, error) {
+	p := &PasswordPolicy{minLength: cfg.MinLength, maxLength: cfg.MaxLength, breached: map[string]struct{}{}}
+	if cfg.BreachedFile == "" {
+		return p, nil
+	}
+
+	file, err := os.Open(cfg.BreachedFile)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo abrir la lista de contraseñas filtradas: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		// Formato de Pwned Passwords: SHA-1 en hexadecimal, opcionalmente con ":conteo"
+		if hash, _, _ := strings.Cut(line, ":"); isSHA1Hex(hash) {
+			p.breached[strings.ToUpper(hash)] = struct{}{}
+			continue
+		}
+		p.breached[sha1Hex(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("no se pudo leer la lista de contraseñas filtradas: %w", err)
+	}
+	return p, nil
+}
+
+// Check devuelve un error de validación sobre field si la contraseña no
+// cumple la política.
+func (p *PasswordPolicy) Check(field, password string) error {
+	length := utf8.RuneCountInString(password)
+	if length < p.minLength {
+		return models.NewValidationError("password_too_short", field, fmt.Sprintf("la contraseña debe tener al menos %d caracteres", p.minLength))
+	}
+	if length > p.maxLength {
+		return models.NewValidationError("password_too_long", field, fmt.Sprintf("la contraseña no puede tener más de %d caracteres", p.maxLength))
+	}
+	if _, ok := p.breached[sha1Hex(password)]; ok {
+		return models.NewValidationError("password_breached", field, "la contraseña aparece en filtraciones conocidas, elija otra")
+	}
+	return nil
+}
+
+// TooLong indica si la contraseña supera la longitud máxima. Las contraseñas
+// más largas se rechazan sin calcular su hash, que sería costoso.
+func (p *PasswordPolicy) TooLong(password string) bool {
+	return utf8.RuneCountInString(password) > p.maxLength
+}
+
+func sha1Hex(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func isSHA1Hex(s string) bool {
+	if len(s) != 2*sha1.Size {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
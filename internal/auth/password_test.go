@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"pt-brm/internal/config"
+	"pt-brm/internal/models"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testPasswordConfig usa parámetros mínimos para que los tests sean rápidos.
+var testPasswordConfig = config.PasswordConfig{
+	MinLength:         12,
+	MaxLength:         64,
+	Argon2Memory:      8192,
+	Argon2Iterations:  1,
+	Argon2Parallelism: 1,
+	MaxConcurrent:     1,
+}
+
+func TestPasswordHasherVerify(t *testing.T) {
+	const password = "correcto caballo batería"
+
+	hasher := NewPasswordHasher(testPasswordConfig)
+	current, err := hasher.Hash(context.Background(), password)
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if !strings.HasPrefix(current, "$argon2id$v=19$m=8192,t=1,p=1$") {
+		t.Fatalf("Hash = %q, no tiene el formato PHC esperado", current)
+	}
+
+	older := testPasswordConfig
+	older.Argon2Iterations = 2
+	previous, err := NewPasswordHasher(older).Hash(context.Background(), password)
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+
+	legacy, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("bcrypt: %v", err)
+	}
+	legacy2y := "$2y$" + strings.TrimPrefix(string(legacy), "$2a$")
+
+	tests := []struct {
+		name       string
+		password   string
+		encoded    string
+		wantOK     bool
+		wantRehash bool
+		wantErr    bool
+	}{
+		{name: "argon2id vigente", password: password, encoded: current, wantOK: true},
+		{name: "argon2id contraseña incorrecta", password: "otra contraseña", encoded: current},
+		{name: "argon2id con otros parámetros", password: password, encoded: previous, wantOK: true, wantRehash: true},
+		{name: "argon2id con otros parámetros y contraseña incorrecta", password: "otra contraseña", encoded: previous},
+		{name: "bcrypt", password: password, encoded: string(legacy), wantOK: true, wantRehash: true},
+		{name: "bcrypt $2y$", password: password, encoded: legacy2y, wantOK: true, wantRehash: true},
+		{name: "bcrypt contraseña incorrecta", password: "otra contraseña", encoded: string(legacy)},
+		{name: "bcrypt truncado", password: password, encoded: "$2a$04$corto", wantErr: true},
+		{name: "formato desconocido", password: password, encoded: "md5$abc", wantErr: true},
+		{name: "vacío", password: password, encoded: "", wantErr: true},
+		{name: "argon2id sin partes", password: password, encoded: "$argon2id$v=19$m=8192", wantErr: true},
+		{name: "argon2id otra versión", password: password, encoded: strings.Replace(current, "v=19", "v=16", 1), wantErr: true},
+		{name: "argon2id parámetros inválidos", password: password, encoded: strings.Replace(current, "m=8192,t=1,p=1", "m=x", 1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, rehash, err := hasher.Verify(context.Background(), tt.password, tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify: error = %v, se esperaba error: %v", err, tt.wantErr)
+			}
+			if ok != tt.wantOK || rehash != tt.wantRehash {
+				t.Errorf("Verify = (%v, %v), se esperaba (%v, %v)", ok, rehash, tt.wantOK, tt.wantRehash)
+			}
+		})
+	}
+
+	if _, _, err := hasher.Verify(context.Background(), password, "md5$abc"); !errors.Is(err, ErrUnknownHash) {
+		t.Errorf("Verify con formato desconocido = %v, se esperaba ErrUnknownHash", err)
+	}
+}
+
+func TestPasswordHasherLimitsConcurrency(t *testing.T) {
+	hasher := NewPasswordHasher(testPasswordConfig)
+	encoded, err := hasher.Hash(context.Background(), "correcto caballo batería")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+
+	// Ocupar el único turno: los cálculos siguientes esperan hasta que se cancele su contexto
+	release, err := hasher.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := hasher.Hash(ctx, "otra contraseña"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Hash con los turnos ocupados = %v, se esperaba DeadlineExceeded", err)
+	}
+	if _, _, err := hasher.Verify(ctx, "correcto caballo batería", encoded); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Verify con los turnos ocupados = %v, se esperaba DeadlineExceeded", err)
+	}
+
+	release()
+	if ok, _, err := hasher.Verify(context.Background(), "correcto caballo batería", encoded); !ok || err != nil {
+		t.Errorf("Verify tras liberar el turno = %v, %v", ok, err)
+	}
+}
+
+func TestPasswordPolicyCheck(t *testing.T) {
+	breached := filepath.Join(t.TempDir(), "breached.txt")
+	content := "contraseña123456\r\n" +
+		// Formato de Pwned Passwords, con conteo y en minúsculas
+		sha1Hex("password12345678") + ":12\n" +
+		strings.ToLower(sha1Hex("qwertyuiopasdf")) + "\n"
+	if err := os.WriteFile(breached, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := testPasswordConfig
+	cfg.BreachedFile = breached
+	policy, err := NewPasswordPolicy(cfg)
+	if err != nil {
+		t.Fatalf("NewPasswordPolicy: %v", err)
+	}
+
+	tests := []struct {
+		password string
+		wantCode string
+	}{
+		{password: "una frase larga y segura"},
+		{password: "ñandú-ñandú!"},
+		{password: "corta", wantCode: "password_too_short"},
+		{password: "ñandú-ñandú", wantCode: "password_too_short"},
+		{password: strings.Repeat("a", 65), wantCode: "password_too_long"},
+		{password: "contraseña123456", wantCode: "password_breached"},
+		{password: "password12345678", wantCode: "password_breached"},
+		{password: "qwertyuiopasdf", wantCode: "password_breached"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			err := policy.Check("new_password", tt.password)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("Check = %v, se esperaba nil", err)
+				}
+				return
+			}
+			var domainErr *models.DomainError
+			if !errors.As(err, &domainErr) || domainErr.Code != tt.wantCode || domainErr.Field != "new_password" {
+				t.Errorf("Check = %v, se esperaba %s sobre new_password", err, tt.wantCode)
+			}
+		})
+	}
+
+	if _, err := NewPasswordPolicy(config.PasswordConfig{BreachedFile: filepath.Join(t.TempDir(), "no-existe")}); err == nil {
+		t.Error("NewPasswordPolicy con un archivo inexistente no devolvió error")
+	}
+}
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"pt-brm/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidToken envuelve cualquier motivo por el que se rechaza un token.
+var ErrInvalidToken = errors.New("token inválido")
+
+// Verifier comprueba la firma y los claims registrados de los tokens.
+type Verifier struct {
+	keys   *KeySet
+	parser *jwt.Parser
+}
+
+// NewVerifier crea un Verifier con las claves y restricciones de cfg.
+func NewVerifier(cfg config.AuthConfig) (*Verifier, error) {
+	keys, err := NewKeySet(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudieron cargar las claves de autenticación: %w", err)
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{AlgHS256, AlgRS256, AlgEdDSA}),
+		jwt.WithLeeway(cfg.ClockSkew),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	}
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+
+	return &Verifier{keys: keys, parser: jwt.NewParser(options...)}, nil
+}
+
+// Verify valida el token y devuelve sus claims. Los errores envuelven
+// ErrInvalidToken y, cuando aplica, jwt.ErrTokenExpired.
+func (v *Verifier) Verify(token string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := v.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.keys.lookup(kid, t.Method.Alg())
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+	return claims, nil
+}
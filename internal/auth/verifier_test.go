@@ -0,0 +1,267 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"pt-brm/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testSecret = "secreto-de-prueba-de-32-caracteres!!"
+
+// writePEM guarda la clave pública en formato PKIX y devuelve la ruta y el contenido.
+func writePEM(t *testing.T, public any) (string, []byte) {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey: %v", err)
+	}
+	content := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	filename := filepath.Join(t.TempDir(), "public.pem")
+	if err := os.WriteFile(filename, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return filename, content
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key any, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+	return signed
+}
+
+func TestVerifierVerify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaFile, rsaPEM := writePEM(t, &rsaKey.PublicKey)
+	edPublic, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edFile, _ := writePEM(t, edPublic)
+
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	jwks := `{"keys":[{"kty":"OKP","crv":"Ed25519","kid":"ed-1","x":"` + base64.RawURLEncoding.EncodeToString(edPublic) + `"}]}`
+	if err := os.WriteFile(jwksFile, []byte(jwks), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	hsConfig := config.AuthConfig{HS256Secret: testSecret, Issuer: "https://auth.example.com", Audience: "pt-brm", ClockSkew: 30 * time.Second}
+	rsaConfig := config.AuthConfig{PublicKeyFile: rsaFile, ClockSkew: 30 * time.Second}
+
+	now := time.Now()
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub":   "1",
+			"iss":   "https://auth.example.com",
+			"aud":   "pt-brm",
+			"iat":   now.Unix(),
+			"exp":   now.Add(time.Minute).Unix(),
+			"scope": "users:read",
+		}
+	}
+	with := func(key string, value any) jwt.MapClaims {
+		claims := valid()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name      string
+		cfg       config.AuthConfig
+		token     string
+		wantErr   bool
+		wantCause error
+	}{
+		{
+			name:  "HS256 válido",
+			cfg:   hsConfig,
+			token: sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", valid()),
+		},
+		{
+			name:  "RS256 válido",
+			cfg:   rsaConfig,
+			token: sign(t, jwt.SigningMethodRS256, rsaKey, "", valid()),
+		},
+		{
+			name:  "EdDSA válido",
+			cfg:   config.AuthConfig{PublicKeyFile: edFile},
+			token: sign(t, jwt.SigningMethodEdDSA, edKey, "", valid()),
+		},
+		{
+			name:  "EdDSA desde JWKS con kid",
+			cfg:   config.AuthConfig{JWKSFile: jwksFile, JWKSRefresh: time.Minute},
+			token: sign(t, jwt.SigningMethodEdDSA, edKey, "ed-1", valid()),
+		},
+		{
+			name:    "kid desconocido en JWKS",
+			cfg:     config.AuthConfig{JWKSFile: jwksFile, JWKSRefresh: time.Minute},
+			token:   sign(t, jwt.SigningMethodEdDSA, edKey, "otro", valid()),
+			wantErr: true,
+		},
+		{
+			name:    "confusión de algoritmo: HS256 firmado con la clave pública RSA",
+			cfg:     rsaConfig,
+			token:   sign(t, jwt.SigningMethodHS256, rsaPEM, "", valid()),
+			wantErr: true,
+		},
+		{
+			name:    "algoritmo none",
+			cfg:     hsConfig,
+			token:   sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "", valid()),
+			wantErr: true,
+		},
+		{
+			name:    "algoritmo no admitido",
+			cfg:     hsConfig,
+			token:   sign(t, jwt.SigningMethodHS512, []byte(testSecret), "", valid()),
+			wantErr: true,
+		},
+		{
+			name:    "secreto incorrecto",
+			cfg:     hsConfig,
+			token:   sign(t, jwt.SigningMethodHS256, []byte("otro-secreto-de-32-caracteres!!!!!"), "", valid()),
+			wantErr: true,
+		},
+		{
+			name:      "expirado",
+			cfg:       hsConfig,
+			token:     sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", with("exp", now.Add(-time.Minute).Unix())),
+			wantErr:   true,
+			wantCause: jwt.ErrTokenExpired,
+		},
+		{
+			name:  "expirado dentro de la tolerancia de reloj",
+			cfg:   hsConfig,
+			token: sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", with("exp", now.Add(-10*time.Second).Unix())),
+		},
+		{
+			name:      "sin exp",
+			cfg:       hsConfig,
+			token:     sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", with("exp", nil)),
+			wantErr:   true,
+			wantCause: jwt.ErrTokenRequiredClaimMissing,
+		},
+		{
+			name:      "emitido en el futuro",
+			cfg:       hsConfig,
+			token:     sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", with("iat", now.Add(time.Hour).Unix())),
+			wantErr:   true,
+			wantCause: jwt.ErrTokenUsedBeforeIssued,
+		},
+		{
+			name:      "audiencia incorrecta",
+			cfg:       hsConfig,
+			token:     sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", with("aud", "otra-api")),
+			wantErr:   true,
+			wantCause: jwt.ErrTokenInvalidAudience,
+		},
+		{
+			name:      "sin audiencia",
+			cfg:       hsConfig,
+			token:     sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", with("aud", nil)),
+			wantErr:   true,
+			wantCause: jwt.ErrTokenRequiredClaimMissing,
+		},
+		{
+			name:      "emisor incorrecto",
+			cfg:       hsConfig,
+			token:     sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", with("iss", "https://evil.example.com")),
+			wantErr:   true,
+			wantCause: jwt.ErrTokenInvalidIssuer,
+		},
+		{
+			name:    "token mal formado",
+			cfg:     hsConfig,
+			token:   "no.es.un-token",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier, err := NewVerifier(tt.cfg)
+			if err != nil {
+				t.Fatalf("NewVerifier: %v", err)
+			}
+
+			claims, err := verifier.Verify(tt.token)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Fatalf("Verify = %v, se esperaba ErrInvalidToken", err)
+				}
+				if tt.wantCause != nil && !errors.Is(err, tt.wantCause) {
+					t.Errorf("Verify = %v, se esperaba %v", err, tt.wantCause)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if claims.Subject != "1" || !claims.HasScope("users:read") {
+				t.Errorf("claims = %+v", claims)
+			}
+		})
+	}
+}
+
+func TestIssuerTokensPassVerifier(t *testing.T) {
+	cfg := config.AuthConfig{
+		HS256Secret: testSecret,
+		Issuer:      "https://auth.example.com",
+		Audience:    "pt-brm",
+		TokenTTL:    15 * time.Minute,
+		LoginScopes: []string{"users:read", "users:write"},
+	}
+
+	issuer, err := NewIssuer(cfg)
+	if err != nil {
+		t.Fatalf("NewIssuer: %v", err)
+	}
+	token, expiresAt, err := issuer.Issue("7")
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	verifier, err := NewVerifier(cfg)
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+	claims, err := verifier.Verify(token)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if claims.Subject != "7" || !claims.HasScope("users:write") || !claims.ExpiresAt.Time.Equal(expiresAt.Truncate(time.Second)) {
+		t.Errorf("claims = %+v, expira %v", claims, expiresAt)
+	}
+
+	if issuer, _ := NewIssuer(config.AuthConfig{}); issuer != nil {
+		t.Errorf("NewIssuer sin claves = %v, se esperaba nil", issuer)
+	}
+}
@@ -1,105 +1,287 @@
 package config
 
 import (
-	"bufio"
+	"context"
 	"fmt"
-	"os"
+	"reflect"
 	"strings"
+	"time"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
+	// Env es el entorno de ejecución (development, test, staging o production).
+	Env string `key:"env" env:"APP_ENV" default:"development" usage:"entorno: development, test, staging o production"`
+	// LogLevel es el nivel mínimo de los logs con nivel (debug, info, warn o error).
+	LogLevel string `key:"log_level" env:"LOG_LEVEL" default:"info" reload:"true" usage:"nivel de log: debug, info, warn o error"`
+	// Features activa o desactiva funciones por nombre de ruta (por ejemplo users.import=false); las no listadas están activas.
+	Features  Features        `key:"features" env:"FEATURE_FLAGS" reload:"true" usage:"funciones activas por ruta (nombre=true|false,...)"`
+	Server    ServerConfig    `key:"server"`
+	RateLimit RateLimitConfig `key:"rate_limit"`
+	Auth      AuthConfig      `key:"auth"`
+	Password  PasswordConfig  `key:"password"`
+	Database  DatabaseConfig  `key:"database"`
+	Purge     PurgeConfig     `key:"purge"`
+	Secrets   SecretsConfig   `key:"secrets"`
+
+	// sources indica de dónde salió cada valor, para config print
+	sources map[string]string
+	// file es el archivo de configuración cargado (-config o CONFIG_FILE), si hay
+	file string
 }
 
+// Entornos de ejecución (APP_ENV).
+const (
+	EnvDevelopment = "development"
+	EnvTest        = "test"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
 type ServerConfig struct {
-	Port string
-	Host string
+	Port string `key:"port" env:"SERVER_PORT" default:"8080" usage:"puerto HTTP"`
+	Host string `key:"host" env:"SERVER_HOST" default:"localhost" usage:"host HTTP"`
+	// CORSOrigins son los orígenes permitidos por CORS ("*" para todos).
+	CORSOrigins []string `key:"cors_origins" env:"CORS_ALLOWED_ORIGINS" default:"*" reload:"true" usage:"orígenes permitidos por CORS (separados por comas)"`
+
+	// Tiempos máximos del servidor HTTP (http.Server); 0 = sin límite.
+	ReadTimeout       time.Duration `key:"read_timeout" env:"SERVER_READ_TIMEOUT" default:"15s" usage:"tiempo máximo para leer una solicitud completa"`
+	ReadHeaderTimeout time.Duration `key:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT" default:"5s" usage:"tiempo máximo para leer los headers de una solicitud"`
+	WriteTimeout      time.Duration `key:"write_timeout" env:"SERVER_WRITE_TIMEOUT" default:"15s" usage:"tiempo máximo para escribir una respuesta"`
+	IdleTimeout       time.Duration `key:"idle_timeout" env:"SERVER_IDLE_TIMEOUT" default:"60s" usage:"tiempo máximo de una conexión keep-alive inactiva"`
+	ShutdownTimeout   time.Duration `key:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT" default:"30s" usage:"tiempo máximo para terminar las solicitudes en curso al apagar"`
+	MaxHeaderBytes    int           `key:"max_header_bytes" env:"SERVER_MAX_HEADER_BYTES" default:"1048576" usage:"tamaño máximo de los headers de una solicitud"`
+	// RouteReadTimeouts y RouteWriteTimeouts reemplazan ReadTimeout y
+	// WriteTimeout en las rutas indicadas por nombre (por ejemplo users.export); 0 = sin límite.
+	RouteReadTimeouts  map[string]time.Duration `key:"route_read_timeouts" env:"SERVER_ROUTE_READ_TIMEOUTS" default:"users.import=5m" usage:"tiempo máximo de lectura por ruta (ruta=duración,...)"`
+	RouteWriteTimeouts map[string]time.Duration `key:"route_write_timeouts" env:"SERVER_ROUTE_WRITE_TIMEOUTS" default:"users.export=0,users.import=5m" usage:"tiempo máximo de escritura por ruta (ruta=duración,...)"`
 }
 
-type DatabaseConfig struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	Database string
-	SSLMode  string
+// RateLimitConfig limita las solicitudes por cliente (IP) con un token bucket.
+type RateLimitConfig struct {
+	// RPS son las solicitudes por segundo permitidas a cada cliente; 0 desactiva el límite.
+	RPS   int `key:"rps" env:"RATE_LIMIT_RPS" default:"0" reload:"true" usage:"solicitudes por segundo por cliente (0 = sin límite)"`
+	Burst int `key:"burst" env:"RATE_LIMIT_BURST" default:"20" reload:"true" usage:"ráfaga máxima de solicitudes por cliente"`
 }
 
-// Carga la configuración desde las variables de entorno y devuelve una instancia de Config.
-func LoadConfig() (*Config, error) {
-	// Cargar variables desde archivo .env si existe
-	if err := loadEnvFile(".env"); err != nil {
-		// Si no existe .env, solo log warning pero continúa
-		fmt.Printf("Warning: .env file not found, using environment variables or defaults\n")
-	}
+// AuthConfig configura la autenticación con JWT (Bearer) de /api/v1. Las
+// claves pueden venir de un secreto HS256, de un archivo PEM con una clave
+// pública (RS256 o EdDSA) o de un archivo JWKS, que se vuelve a leer cuando
+// cambia para rotar las claves. Los tokens de POST /auth/login se firman con
+// la clave privada de SigningKeyFile o, si no hay, con HS256Secret.
+type AuthConfig struct {
+	Enabled       bool          `key:"enabled" env:"AUTH_ENABLED" default:"true" usage:"exigir un JWT válido en /api/v1"`
+	Issuer        string        `key:"issuer" env:"AUTH_ISSUER" usage:"emisor (iss) esperado en los tokens"`
+	Audience      string        `key:"audience" env:"AUTH_AUDIENCE" usage:"audiencia (aud) esperada en los tokens"`
+	ClockSkew     time.Duration `key:"clock_skew" env:"AUTH_CLOCK_SKEW" default:"30s" usage:"tolerancia de reloj al validar exp, nbf e iat"`
+	HS256Secret   string        `key:"hs256_secret" env:"AUTH_HS256_SECRET" secret:"true" usage:"secreto compartido para tokens HS256"`
+	PublicKeyFile string        `key:"public_key_file" env:"AUTH_PUBLIC_KEY_FILE" usage:"archivo PEM con la clave pública RSA o Ed25519"`
+	JWKSFile      string        `key:"jwks_file" env:"AUTH_JWKS_FILE" usage:"archivo JWKS con las claves de verificación"`
+	JWKSRefresh   time.Duration `key:"jwks_refresh" env:"AUTH_JWKS_REFRESH" default:"1m" usage:"frecuencia con la que se revisa si cambió el archivo JWKS"`
+
+	SigningKeyFile string        `key:"signing_key_file" env:"AUTH_SIGNING_KEY_FILE" usage:"archivo PEM con la clave privada RSA o Ed25519 para firmar los tokens de login"`
+	SigningKeyID   string        `key:"signing_key_id" env:"AUTH_SIGNING_KEY_ID" usage:"kid de los tokens firmados con la clave privada"`
+	TokenTTL       time.Duration `key:"token_ttl" env:"AUTH_TOKEN_TTL" default:"15m" usage:"vigencia de los tokens de login"`
+	// LoginScopes son los permisos de los tokens que se obtienen con usuario y contraseña.
+	LoginScopes []string `key:"login_scopes" env:"AUTH_LOGIN_SCOPES" default:"users:read" usage:"permisos de los tokens de login (separados por comas)"`
+}
+
+// PasswordConfig define la política de contraseñas y los parámetros de
+// argon2id. Al cambiar los parámetros, los hashes existentes se actualizan la
+// próxima vez que el usuario inicia sesión.
+type PasswordConfig struct {
+	MinLength int `key:"min_length" env:"PASSWORD_MIN_LENGTH" default:"12" usage:"longitud mínima de las contraseñas"`
+	MaxLength int `key:"max_length" env:"PASSWORD_MAX_LENGTH" default:"128" usage:"longitud máxima de las contraseñas"`
+	// BreachedFile es una lista de contraseñas filtradas, una por línea, en
+	// claro o como SHA-1 en hexadecimal (formato de Pwned Passwords, "HASH:conteo").
+	BreachedFile      string `key:"breached_file" env:"PASSWORD_BREACHED_FILE" usage:"archivo con contraseñas filtradas que no se permiten"`
+	Argon2Memory      int    `key:"argon2_memory" env:"PASSWORD_ARGON2_MEMORY" default:"65536" usage:"memoria de argon2id en KiB"`
+	Argon2Iterations  int    `key:"argon2_iterations" env:"PASSWORD_ARGON2_ITERATIONS" default:"3" usage:"iteraciones de argon2id"`
+	Argon2Parallelism int    `key:"argon2_parallelism" env:"PASSWORD_ARGON2_PARALLELISM" default:"2" usage:"hilos de argon2id"`
+	// MaxConcurrent limita los hashes que se calculan a la vez, porque cada uno
+	// reserva Argon2Memory KiB; las demás solicitudes esperan su turno.
+	MaxConcurrent int `key:"max_concurrent" env:"PASSWORD_MAX_CONCURRENT" default:"4" usage:"máximo de hashes de contraseña calculados a la vez"`
+}
+
+// Features indica qué funciones están activas, por nombre de ruta.
+type Features map[string]bool
+
+// Enabled indica si la función está activa; las no configuradas lo están.
+func (f Features) Enabled(name string) bool {
+	enabled, ok := f[name]
+	return !ok || enabled
+}
+
+// Motores de base de datos soportados (DB_DRIVER).
+const (
+	DriverMySQL    = "mysql"
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// Modos de DB_SSL_MODE para la conexión con la base de datos.
+const (
+	// SSLDisable conecta sin TLS.
+	SSLDisable = "disable"
+	// SSLPreferred usa TLS si el servidor lo soporta, sin verificar el certificado.
+	SSLPreferred = "preferred"
+	// SSLRequired exige TLS, sin verificar el certificado.
+	SSLRequired = "required"
+	// SSLVerifyCA exige TLS y que el certificado esté firmado por DB_SSL_CA.
+	SSLVerifyCA = "verify-ca"
+	// SSLVerifyFull además verifica que el certificado corresponda a DB_HOST.
+	SSLVerifyFull = "verify-full"
+)
+
+// MySQLTLSConfigName es el nombre con el que se registra la configuración TLS
+// en el driver de MySQL (mysql.RegisterTLSConfig) y se referencia en el DSN.
+const MySQLTLSConfigName = "pt-brm"
+
+type DatabaseConfig struct {
+	Driver   string `key:"driver" env:"DB_DRIVER" default:"mysql" usage:"motor: mysql, sqlite o postgres"`
+	Host     string `key:"host" env:"DB_HOST" default:"localhost" usage:"host de la base de datos"`
+	Port     string `key:"port" env:"DB_PORT" usage:"puerto de la base de datos (por defecto 3306, o 5432 en postgres)"`
+	User     string `key:"user" env:"DB_USER" default:"root" credential:"true" usage:"usuario de la base de datos"`
+	Password string `key:"password" env:"DB_PASSWORD" default:"password" secret:"true" credential:"true" usage:"contraseña de la base de datos"`
+	Database string `key:"name" env:"DB_NAME" default:"database" usage:"nombre de la base de datos"`
+	SSLMode  string `key:"ssl_mode" env:"DB_SSL_MODE" default:"disable" usage:"TLS: disable, preferred, required, verify-ca o verify-full"`
+	// Certificados para TLS: CA del servidor y certificado/clave del cliente (opcionales).
+	SSLCA   string `key:"ssl_ca" env:"DB_SSL_CA" usage:"archivo PEM con la CA del servidor"`
+	SSLCert string `key:"ssl_cert" env:"DB_SSL_CERT" usage:"archivo PEM con el certificado de cliente"`
+	SSLKey  string `key:"ssl_key" env:"DB_SSL_KEY" usage:"archivo PEM con la clave del certificado de cliente"`
+	// Path es el archivo de la base de datos cuando Driver es sqlite (":memory:" para una en memoria).
+	Path string `key:"path" env:"DB_PATH" default:"users.db" usage:"archivo de la base de datos sqlite"`
 
-	return &Config{
-		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", "8080"),
-			Host: getEnv("SERVER_HOST", "localhost"),
-		},
-		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnv("DB_PORT", "3306"),
-			User:     getEnv("DB_USER", "root"),
-			Password: getEnv("DB_PASSWORD", "password"),
-			Database: getEnv("DB_NAME", "database"),
-			SSLMode:  getEnv("DB_SSL_MODE", "disable"),
-		},
-	}, nil
+	// Pool de conexiones; no aplica a sqlite, que usa una sola conexión.
+	MaxOpenConns    int           `key:"max_open_conns" env:"DB_MAX_OPEN_CONNS" default:"25" reload:"true" usage:"conexiones abiertas máximas (0 = sin límite)"`
+	MaxIdleConns    int           `key:"max_idle_conns" env:"DB_MAX_IDLE_CONNS" default:"25" reload:"true" usage:"conexiones inactivas máximas en el pool"`
+	ConnMaxLifetime time.Duration `key:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" default:"5m" reload:"true" usage:"tiempo máximo de vida de una conexión (0 = sin límite)"`
+	ConnMaxIdleTime time.Duration `key:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME" default:"0" reload:"true" usage:"tiempo máximo que una conexión puede estar inactiva (0 = sin límite)"`
+
+	// Reintentos de la conexión inicial y las migraciones al arrancar:
+	// espera exponencial con jitter desde ConnectBackoff hasta ConnectMaxBackoff,
+	// durante como máximo ConnectMaxWait (0 = un solo intento).
+	ConnectMaxWait    time.Duration `key:"connect_max_wait" env:"DB_CONNECT_MAX_WAIT" default:"2m" usage:"tiempo máximo reintentando la conexión inicial (0 = un intento)"`
+	ConnectBackoff    time.Duration `key:"connect_backoff" env:"DB_CONNECT_BACKOFF" default:"500ms" usage:"espera inicial entre reintentos de conexión"`
+	ConnectMaxBackoff time.Duration `key:"connect_max_backoff" env:"DB_CONNECT_MAX_BACKOFF" default:"15s" usage:"espera máxima entre reintentos de conexión"`
+
+	// Tiempo máximo de cada tipo de operación sobre la base de datos; 0 = sin límite.
+	ReadTimeout   time.Duration `key:"read_timeout" env:"DB_READ_TIMEOUT" default:"5s" usage:"tiempo máximo de las lecturas"`
+	WriteTimeout  time.Duration `key:"write_timeout" env:"DB_WRITE_TIMEOUT" default:"5s" usage:"tiempo máximo de las escrituras"`
+	BulkTimeout   time.Duration `key:"bulk_timeout" env:"DB_BULK_TIMEOUT" default:"1m" usage:"tiempo máximo de las operaciones masivas"`
+	ExportTimeout time.Duration `key:"export_timeout" env:"DB_EXPORT_TIMEOUT" default:"0" usage:"tiempo máximo de las exportaciones"`
+
+	// rotating son las credenciales que salieron de un archivo *_FILE o de un
+	// SecretProvider, por nombre de variable; se vuelven a leer en cada conexión.
+	rotating map[string]SecretProvider
+}
+
+// PurgeConfig controla la eliminación definitiva de usuarios eliminados (soft delete).
+type PurgeConfig struct {
+	// Retention es el tiempo que un usuario eliminado se conserva antes de purgarlo; 0 desactiva el job.
+	Retention time.Duration `key:"retention" env:"USERS_PURGE_RETENTION" default:"720h" usage:"retención de usuarios eliminados (0 desactiva la purga)"`
+	// Interval es la frecuencia con la que se ejecuta el job de purga.
+	Interval time.Duration `key:"interval" env:"USERS_PURGE_INTERVAL" default:"1h" usage:"frecuencia del job de purga"`
 }
 
 // Genera una cadena de conexión para la base de datos.
 func (c *DatabaseConfig) GetDSN() string {
-	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+	switch c.Driver {
+	case DriverSQLite:
+		// _time_format=sqlite guarda las fechas como texto comparable ("2006-01-02 15:04:05-07:00")
+		return fmt.Sprintf("file:%s?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_time_format=sqlite", c.Path)
+	case DriverPostgres:
+		// Formato clave=valor de libpq
+		dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+			libpqValue(c.Host),
+			libpqValue(c.Port),
+			libpqValue(c.User),
+			libpqValue(c.Password),
+			libpqValue(c.Database),
+			libpqValue(c.postgresSSLMode()),
+		)
+		for key, value := range map[string]string{"sslrootcert": c.SSLCA, "sslcert": c.SSLCert, "sslkey": c.SSLKey} {
+			if value != "" {
+				dsn += " " + key + "=" + libpqValue(value)
+			}
+		}
+		return dsn
+	}
+
+	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local&tls=%s",
 		c.User,
 		c.Password,
 		c.Host,
 		c.Port,
 		c.Database,
+		c.mysqlTLS(),
 	)
 }
 
-// loadEnvFile carga variables de entorno desde un archivo .env
-func loadEnvFile(filename string) error {
-	file, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
+// RotatesCredentials indica si las credenciales se vuelven a leer al abrir cada conexión.
+func (c DatabaseConfig) RotatesCredentials() bool {
+	return len(c.rotating) > 0
+}
 
-		// Saltar líneas vacías y comentarios
-		if line == "" || strings.HasPrefix(line, "#") {
+// CurrentDSN vuelve a leer las credenciales rotables y devuelve el DSN con
+// sus valores actuales.
+func (c DatabaseConfig) CurrentDSN(ctx context.Context) (string, error) {
+	fields := collectFields(reflect.ValueOf(&c).Elem(), "")
+	for _, f := range fields {
+		provider, ok := c.rotating[f.env]
+		if !ok {
 			continue
 		}
-
-		// Dividir en clave=valor
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			continue
+		value, err := provider.GetSecret(ctx, f.env)
+		if err != nil {
+			return "", fmt.Errorf("no se pudo leer %s: %w", f.env, err)
 		}
+		f.value.SetString(value)
+	}
+	return c.GetDSN(), nil
+}
 
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
+// mysqlTLS devuelve el valor del parámetro tls del DSN de MySQL. Los modos
+// que usan certificados referencian la configuración registrada por database.
+func (c *DatabaseConfig) mysqlTLS() string {
+	switch c.SSLMode {
+	case SSLDisable:
+		return "false"
+	case SSLPreferred:
+		return "preferred"
+	default:
+		return MySQLTLSConfigName
+	}
+}
 
-		// Solo establecer si no existe ya en el entorno
-		if os.Getenv(key) == "" {
-			os.Setenv(key, value)
-		}
+// postgresSSLMode traduce el modo al sslmode de libpq.
+func (c *DatabaseConfig) postgresSSLMode() string {
+	switch c.SSLMode {
+	case SSLPreferred:
+		return "prefer"
+	case SSLRequired:
+		return "require"
+	default:
+		return c.SSLMode
 	}
+}
 
-	return scanner.Err()
+// normalizeSSLMode acepta también los nombres de libpq (prefer, require).
+func normalizeSSLMode(mode string) string {
+	switch mode = strings.ToLower(strings.TrimSpace(mode)); mode {
+	case "prefer":
+		return SSLPreferred
+	case "require":
+		return SSLRequired
+	}
+	return mode
 }
 
-// Obtiene el valor de una variable de entorno o devuelve un valor por defecto si no está definida.
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
+// libpqValue entrecomilla un valor del DSN de libpq si está vacío o contiene
+// espacios, comillas o barras invertidas.
+func libpqValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` '\`) {
 		return value
 	}
-	return defaultValue
+	replacer := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	return "'" + replacer.Replace(value) + "'"
 }
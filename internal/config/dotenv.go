@@ -0,0 +1,465 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+)
+
+// envFromFiles son las variables que estableció algún archivo .env; en una
+// recarga se pueden volver a leer, a diferencia de las del entorno del proceso.
+var (
+	envFromFilesMu sync.Mutex
+	envFromFiles   = make(map[string]bool)
+)
+
+// loadEnvFiles carga las variables de los archivos .env sin pisar las que ya
+// vienen del entorno del proceso. Si ENV_FILE está definido (una o varias
+// rutas separadas por comas) se cargan solo esos archivos y todos deben existir.
+// Si no, se cargan por capas .env, .env.local y .env.<APP_ENV>: los que no
+// existen se ignoran y cada capa tiene prioridad sobre las anteriores.
+// Devuelve los archivos cargados.
+//
+// Los archivos se leen completos antes de tocar el entorno, y luego solo se
+// cambian las variables que cambiaron y se quitan las que ya no están: en una
+// recarga, otros componentes (como los secretos de la base de datos) pueden
+// leer el entorno en cualquier momento y nunca deben ver una variable vacía.
+// Si algún archivo falla, el entorno queda como estaba.
+func loadEnvFiles() ([]string, error) {
+	envFromFilesMu.Lock()
+	defer envFromFilesMu.Unlock()
+
+	// Las variables del proceso siempre tienen prioridad sobre los archivos; las
+	// que puso la carga anterior no cuentan, se vuelven a leer de los archivos
+	protected := make(map[string]bool)
+	for _, kv := range os.Environ() {
+		key, _, _ := strings.Cut(kv, "=")
+		if !envFromFiles[key] {
+			protected[key] = true
+		}
+	}
+
+	layers := &envLayers{protected: protected, values: make(map[string]string)}
+	loaded, err := layers.load()
+	if err != nil {
+		return loaded, err
+	}
+
+	for key, value := range layers.values {
+		if current, ok := os.LookupEnv(key); ok && current == value {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return loaded, fmt.Errorf("no se pudo establecer %s: %w", key, err)
+		}
+	}
+	for key := range envFromFiles {
+		if _, ok := layers.values[key]; !ok {
+			os.Unsetenv(key)
+		}
+	}
+
+	clear(envFromFiles)
+	for key := range layers.values {
+		envFromFiles[key] = true
+	}
+	return loaded, nil
+}
+
+// envLayers acumula los valores de los archivos .env antes de aplicarlos al
+// entorno del proceso.
+type envLayers struct {
+	// protected son las variables del entorno del proceso, que no se sobrescriben
+	protected map[string]bool
+	// values son las variables leídas de los archivos, por clave
+	values map[string]string
+}
+
+// lookup busca una variable como si los valores leídos ya estuvieran en el
+// entorno y los de la carga anterior no.
+func (l *envLayers) lookup(key string) (string, bool) {
+	if value, ok := l.values[key]; ok {
+		return value, true
+	}
+	if !l.protected[key] {
+		return "", false
+	}
+	return os.LookupEnv(key)
+}
+
+// load lee los archivos que correspondan y devuelve los que se cargaron.
+func (l *envLayers) load() ([]string, error) {
+	if envFile, _ := l.lookup("ENV_FILE"); envFile != "" {
+		var loaded []string
+		for _, filename := range strings.Split(envFile, ",") {
+			filename = strings.TrimSpace(filename)
+			if err := l.loadFile(filename); err != nil {
+				return loaded, err
+			}
+			loaded = append(loaded, filename)
+		}
+		return loaded, nil
+	}
+
+	var loaded []string
+	for _, filename := range []string{".env", ".env.local"} {
+		ok, err := l.loadOptionalFile(filename)
+		if err != nil {
+			return loaded, err
+		}
+		if ok {
+			loaded = append(loaded, filename)
+		}
+	}
+
+	// APP_ENV puede venir del entorno o de los archivos anteriores
+	if env, _ := l.lookup("APP_ENV"); env != "" {
+		filename := ".env." + env
+		ok, err := l.loadOptionalFile(filename)
+		if err != nil {
+			return loaded, err
+		}
+		if ok {
+			loaded = append(loaded, filename)
+		}
+	}
+
+	return loaded, nil
+}
+
+// loadOptionalFile carga filename si existe; indica si se cargó.
+func (l *envLayers) loadOptionalFile(filename string) (bool, error) {
+	err := l.loadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// loadFile lee las variables de un archivo .env. Las claves protegidas (las
+// del entorno del proceso) no se sobrescriben.
+func (l *envLayers) loadFile(filename string) error {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	entries, err := parseDotenv(string(content), l.lookup)
+	if err != nil {
+		return fmt.Errorf("%s:%w", filename, err)
+	}
+
+	for _, entry := range entries {
+		if l.protected[entry.key] {
+			continue
+		}
+		l.values[entry.key] = entry.value
+	}
+	return nil
+}
+
+// envEntry es una asignación CLAVE=valor de un archivo .env.
+type envEntry struct {
+	key   string
+	value string
+	line  int
+}
+
+// parseDotenv interpreta el contenido de un archivo .env:
+//
+//	# comentario
+//	export CLAVE=valor             # comentario en línea
+//	SIMPLE='literal, sin ${EXPANSION}'
+//	DOBLE="con escapes \n \t \" \\ \$ y ${OTRA:-por defecto}"
+//	MULTILINEA="primera línea
+//	segunda línea"
+//
+// Los valores sin comillas y entre comillas dobles expanden $VAR, ${VAR} y
+// ${VAR:-defecto} con las variables definidas antes en el mismo archivo o, si
+// no, con lookup. Los errores indican el número de línea.
+func parseDotenv(content string, lookup func(string) (string, bool)) ([]envEntry, error) {
+	p := &dotenvParser{
+		src:    strings.ReplaceAll(content, "\r\n", "\n"),
+		line:   1,
+		lookup: lookup,
+		vars:   make(map[string]string),
+	}
+	return p.parse()
+}
+
+type dotenvParser struct {
+	src    string
+	pos    int
+	line   int
+	lookup func(string) (string, bool)
+	vars   map[string]string
+}
+
+func (p *dotenvParser) errorf(line int, format string, args ...any) error {
+	return fmt.Errorf("%d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func (p *dotenvParser) parse() ([]envEntry, error) {
+	var entries []envEntry
+	for p.pos < len(p.src) {
+		p.skipSpaces()
+		if p.pos >= len(p.src) {
+			break
+		}
+
+		// Líneas vacías y comentarios
+		if c := p.src[p.pos]; c == '\n' || c == '#' {
+			p.skipLine()
+			continue
+		}
+
+		line := p.line
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+
+		p.vars[key] = value
+		entries = append(entries, envEntry{key: key, value: value, line: line})
+	}
+	return entries, nil
+}
+
+// parseKey lee "[export ]CLAVE =" y deja el cursor al inicio del valor.
+func (p *dotenvParser) parseKey() (string, error) {
+	if rest := p.src[p.pos:]; strings.HasPrefix(rest, "export ") || strings.HasPrefix(rest, "export\t") {
+		p.pos += len("export")
+		p.skipSpaces()
+	}
+
+	start := p.pos
+	for p.pos < len(p.src) && isKeyChar(p.src[p.pos], p.pos == start) {
+		p.pos++
+	}
+	key := p.src[start:p.pos]
+	p.skipSpaces()
+
+	if p.pos >= len(p.src) || p.src[p.pos] != '=' {
+		if key == "" {
+			return "", p.errorf(p.line, "nombre de variable no válido en %q", p.currentLine())
+		}
+		return "", p.errorf(p.line, "falta '=' después de %s", key)
+	}
+	if key == "" {
+		return "", p.errorf(p.line, "falta el nombre de la variable antes de '='")
+	}
+	p.pos++ // '='
+	p.skipSpaces()
+	return key, nil
+}
+
+// parseValue lee el valor hasta el final de la línea (o de las comillas) y
+// consume el resto de la línea, que solo puede contener un comentario.
+func (p *dotenvParser) parseValue() (string, error) {
+	if p.pos >= len(p.src) {
+		return "", nil
+	}
+
+	switch p.src[p.pos] {
+	case '\'':
+		value, err := p.parseQuoted('\'')
+		if err != nil {
+			return "", err
+		}
+		return value, p.endOfValue()
+	case '"':
+		line := p.line
+		raw, err := p.parseQuoted('"')
+		if err != nil {
+			return "", err
+		}
+		value, err := p.expand(raw, line)
+		if err != nil {
+			return "", err
+		}
+		return value, p.endOfValue()
+	}
+
+	// Sin comillas: hasta el fin de línea o un '#' precedido de espacio
+	line, start := p.line, p.pos
+	for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+		if p.src[p.pos] == '#' && (p.src[p.pos-1] == ' ' || p.src[p.pos-1] == '\t') {
+			break
+		}
+		p.pos++
+	}
+	raw := strings.TrimSpace(p.src[start:p.pos])
+	p.skipLine()
+	return p.expand(raw, line)
+}
+
+// parseQuoted lee un valor entre comillas, que puede ocupar varias líneas.
+// Entre comillas dobles se interpretan los escapes; entre simples el valor es literal.
+func (p *dotenvParser) parseQuoted(quote byte) (string, error) {
+	startLine := p.line
+	p.pos++ // comilla de apertura
+
+	var b strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return b.String(), nil
+		case c == '\\' && quote == '"' && p.pos+1 < len(p.src):
+			p.pos++
+			switch next := p.src[p.pos]; next {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '"', '\\':
+				b.WriteByte(next)
+			case '$':
+				// Se protege para que expand no lo interprete
+				b.WriteString(escapedDollar)
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(next)
+			}
+		default:
+			if c == '\n' {
+				p.line++
+			}
+			b.WriteByte(c)
+		}
+		p.pos++
+	}
+	return "", p.errorf(startLine, "falta la comilla de cierre %c", quote)
+}
+
+// endOfValue consume el resto de la línea tras un valor entre comillas.
+func (p *dotenvParser) endOfValue() error {
+	p.skipSpaces()
+	if p.pos < len(p.src) && p.src[p.pos] != '\n' && p.src[p.pos] != '#' {
+		return p.errorf(p.line, "contenido inesperado después de las comillas: %q", p.currentLine())
+	}
+	p.skipLine()
+	return nil
+}
+
+// escapedDollar marca un "\$" ya interpretado dentro de comillas dobles.
+const escapedDollar = "\x00$"
+
+// expand sustituye $VAR, ${VAR} y ${VAR:-defecto}.
+func (p *dotenvParser) expand(value string, line int) (string, error) {
+	if !strings.Contains(value, "$") {
+		return value, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c == 0 && strings.HasPrefix(value[i:], escapedDollar) {
+			b.WriteByte('$')
+			i++
+			continue
+		}
+		if c != '$' || i+1 >= len(value) {
+			b.WriteByte(c)
+			continue
+		}
+
+		if value[i+1] == '{' {
+			end := strings.IndexByte(value[i:], '}')
+			if end < 0 {
+				return "", p.errorf(line, "falta '}' en %q", value[i:])
+			}
+			name, fallback, hasFallback := strings.Cut(value[i+2:i+end], ":-")
+			if !isValidKey(name) {
+				return "", p.errorf(line, "nombre de variable no válido en ${%s}", value[i+2:i+end])
+			}
+			if v, ok := p.resolve(name); ok && v != "" {
+				b.WriteString(v)
+			} else if hasFallback {
+				b.WriteString(fallback)
+			}
+			i += end
+			continue
+		}
+
+		j := i + 1
+		for j < len(value) && isKeyChar(value[j], j == i+1) {
+			j++
+		}
+		if j == i+1 {
+			// "$" suelto, se deja tal cual
+			b.WriteByte(c)
+			continue
+		}
+		v, _ := p.resolve(value[i+1 : j])
+		b.WriteString(v)
+		i = j - 1
+	}
+	return b.String(), nil
+}
+
+// resolve busca una variable definida antes en el archivo o en lookup.
+func (p *dotenvParser) resolve(name string) (string, bool) {
+	if v, ok := p.vars[name]; ok {
+		return v, true
+	}
+	return p.lookup(name)
+}
+
+func (p *dotenvParser) skipSpaces() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// skipLine avanza hasta después del siguiente salto de línea.
+func (p *dotenvParser) skipLine() {
+	if i := strings.IndexByte(p.src[p.pos:], '\n'); i >= 0 {
+		p.pos += i + 1
+		p.line++
+		return
+	}
+	p.pos = len(p.src)
+}
+
+// currentLine devuelve la línea del cursor, para los mensajes de error.
+func (p *dotenvParser) currentLine() string {
+	start := strings.LastIndexByte(p.src[:p.pos], '\n') + 1
+	end := strings.IndexByte(p.src[p.pos:], '\n')
+	if end < 0 {
+		return p.src[start:]
+	}
+	return p.src[start : p.pos+end]
+}
+
+func isKeyChar(c byte, first bool) bool {
+	switch {
+	case c == '_', c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z':
+		return true
+	case c >= '0' && c <= '9', c == '.':
+		return !first
+	}
+	return false
+}
+
+func isValidKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if !isKeyChar(key[i], i == 0) {
+			return false
+		}
+	}
+	return true
+}
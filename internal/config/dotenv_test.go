@@ -0,0 +1,155 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	env := map[string]string{"HOME": "/home/app", "EMPTY": ""}
+	lookup := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    []envEntry
+		wantErr string
+	}{
+		{
+			name:    "simple",
+			content: "A=1\nB = dos \n",
+			want:    []envEntry{{"A", "1", 1}, {"B", "dos", 2}},
+		},
+		{
+			name:    "comentarios y export",
+			content: "# comentario\n\nexport A=1 # en línea\nB=a#b\n",
+			want:    []envEntry{{"A", "1", 3}, {"B", "a#b", 4}},
+		},
+		{
+			name:    "valor vacío",
+			content: "A=\nB=",
+			want:    []envEntry{{"A", "", 1}, {"B", "", 2}},
+		},
+		{
+			name:    "comillas simples literales",
+			content: `A='x ${HOME} \n' # c`,
+			want:    []envEntry{{"A", `x ${HOME} \n`, 1}},
+		},
+		{
+			name:    "escapes entre comillas dobles",
+			content: `A="a\nb\t\"c\" \\ \$HOME"`,
+			want:    []envEntry{{"A", "a\nb\t\"c\" \\ $HOME", 1}},
+		},
+		{
+			name:    "multilínea",
+			content: "A=\"uno\ndos\"\nB=3\n",
+			want:    []envEntry{{"A", "uno\ndos", 1}, {"B", "3", 3}},
+		},
+		{
+			name:    "expansión",
+			content: "BASE=/srv\nA=$BASE/data\nB=${HOME}/x\nC=\"${MISSING:-def}\"\nD=${EMPTY:-vacía}\nE=$MISSING.\nF=costo $ 5\n",
+			want: []envEntry{
+				{"BASE", "/srv", 1},
+				{"A", "/srv/data", 2},
+				{"B", "/home/app/x", 3},
+				{"C", "def", 4},
+				{"D", "vacía", 5},
+				{"E", "", 6},
+				{"F", "costo $ 5", 7},
+			},
+		},
+		{
+			name:    "CRLF",
+			content: "A=1\r\nB=\"2\"\r\n",
+			want:    []envEntry{{"A", "1", 1}, {"B", "2", 2}},
+		},
+		{name: "sin igual", content: "A=1\nSOLO\n", wantErr: "2: falta '=' después de SOLO"},
+		{name: "sin nombre", content: "=1", wantErr: "1: falta el nombre"},
+		{name: "nombre inválido", content: "1A=x", wantErr: "1: nombre de variable no válido"},
+		{name: "comilla sin cerrar", content: "A=1\nB=\"abc\n", wantErr: "2: falta la comilla de cierre"},
+		{name: "contenido tras comillas", content: `A="x" y`, wantErr: "1: contenido inesperado"},
+		{name: "llave sin cerrar", content: "A=${HOME", wantErr: "1: falta '}'"},
+		{name: "variable inválida", content: "A=${1X}", wantErr: "1: nombre de variable no válido en ${1X}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDotenv(tt.content, lookup)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseDotenv = %v, se esperaba un error con %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseDotenv: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseDotenv = %+v, se esperaba %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("entrada %d = %+v, se esperaba %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLoadEnvFilesReload(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("ENV_FILE", "")
+	t.Setenv("APP_ENV", "")
+	t.Setenv("PTBRM_TEST_PROCESS", "proceso")
+	t.Cleanup(func() {
+		for _, key := range []string{"PTBRM_TEST_A", "PTBRM_TEST_B", "PTBRM_TEST_C"} {
+			os.Unsetenv(key)
+		}
+		clear(envFromFiles)
+	})
+
+	steps := []struct {
+		name    string
+		content string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "carga inicial",
+			content: "PTBRM_TEST_A=1\nPTBRM_TEST_B=${PTBRM_TEST_A}/b\nPTBRM_TEST_PROCESS=archivo\n",
+			want:    map[string]string{"PTBRM_TEST_A": "1", "PTBRM_TEST_B": "1/b", "PTBRM_TEST_PROCESS": "proceso"},
+		},
+		{
+			name:    "cambio y variable quitada",
+			content: "PTBRM_TEST_A=2\nPTBRM_TEST_C=$PTBRM_TEST_B\n",
+			want:    map[string]string{"PTBRM_TEST_A": "2", "PTBRM_TEST_B": "", "PTBRM_TEST_C": "", "PTBRM_TEST_PROCESS": "proceso"},
+		},
+		{
+			name:    "archivo inválido conserva el entorno",
+			content: "PTBRM_TEST_A=3\nSOLO\n",
+			want:    map[string]string{"PTBRM_TEST_A": "2", "PTBRM_TEST_B": "", "PTBRM_TEST_C": ""},
+			wantErr: true,
+		},
+	}
+
+	for _, step := range steps {
+		if err := os.WriteFile(filepath.Join(dir, ".env"), []byte(step.content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		_, err := loadEnvFiles()
+		if (err != nil) != step.wantErr {
+			t.Fatalf("%s: loadEnvFiles() error = %v, se esperaba error: %v", step.name, err, step.wantErr)
+		}
+		for key, want := range step.want {
+			if got := os.Getenv(key); got != want {
+				t.Errorf("%s: %s = %q, se esperaba %q", step.name, key, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,614 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Orígenes de un valor de configuración, de menor a mayor prioridad.
+const (
+	SourceDefault = "defecto"
+	SourceFile    = "archivo"
+	SourceEnv     = "entorno"
+	SourceSecret  = "secreto"
+	SourceFlag    = "flag"
+)
+
+// redacted reemplaza el valor de los campos secretos en config print.
+const redacted = "********"
+
+// Setting es el valor efectivo de un campo de la configuración.
+type Setting struct {
+	Key    string // clave en el archivo, por ejemplo "database.host"
+	Env    string // variable de entorno, por ejemplo "DB_HOST"
+	Flag   string // flag de línea de comandos, por ejemplo "db-host"
+	Value  string // valor formateado; los secretos se muestran redactados
+	Source string
+}
+
+// ValidationError agrupa todos los problemas encontrados al cargar la configuración.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "configuración inválida:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// LoadConfig carga la configuración con esta prioridad (de menor a mayor):
+// valores por defecto, archivo YAML/TOML (-config o CONFIG_FILE), variables de
+// entorno (incluidos los archivos .env) y flags de línea de comandos. args no
+// incluye el nombre del programa; se devuelven los argumentos que quedan
+// después de los flags (el subcomando).
+//
+// Si algún valor es inválido se devuelve un *ValidationError con todos los
+// problemas junto con la configuración cargada, para poder mostrarla.
+func LoadConfig(args []string) (*Config, []string, error) {
+	// Cargar variables desde los archivos .env (ENV_FILE o .env, .env.local y .env.<APP_ENV>)
+	loaded, err := loadEnvFiles()
+	if err != nil {
+		return nil, nil, fmt.Errorf("no se pudo cargar el archivo de entorno: %w", err)
+	}
+	if len(loaded) == 0 {
+		// Si no hay ningún .env, solo log warning pero continúa
+		fmt.Printf("Warning: .env file not found, using environment variables or defaults\n")
+	}
+
+	cfg := &Config{sources: make(map[string]string)}
+	cfg.Database.rotating = make(map[string]SecretProvider)
+	fields := cfg.fields()
+
+	flags := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+	configFile := flags.String("config", os.Getenv("CONFIG_FILE"), "archivo de configuración YAML o TOML (CONFIG_FILE)")
+	flagValues := make(map[string]*string, len(fields))
+	for _, f := range fields {
+		flagValues[f.key] = flags.String(f.flag(), "", fmt.Sprintf("%s (%s)", f.usage, f.env))
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	setFlags := make(map[string]bool)
+	flags.Visit(func(fl *flag.Flag) {
+		setFlags[fl.Name] = true
+	})
+
+	fileValues, err := readConfigFile(*configFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg.file = *configFile
+
+	var problems []string
+	for _, f := range fields {
+		raw, source := f.def, SourceDefault
+		if value, ok := takeFileValue(fileValues, f); ok {
+			raw, source = value, SourceFile
+		}
+		if value := os.Getenv(f.env); value != "" {
+			raw, source = value, SourceEnv
+		} else if os.Getenv(f.env+"_FILE") != "" {
+			// Variante *_FILE (secretos de Docker/Kubernetes)
+			value, err := EnvSecretProvider{}.GetSecret(context.Background(), f.env)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s (%s_FILE): %v", f.key, f.env, err))
+			}
+			raw, source = value, SourceSecret
+			if f.credential {
+				cfg.Database.rotating[f.env] = EnvSecretProvider{}
+			}
+		}
+		if setFlags[f.flag()] {
+			raw, source = *flagValues[f.key], SourceFlag
+			delete(cfg.Database.rotating, f.env)
+		}
+
+		if err := setValue(f.value, raw); err != nil {
+			problems = append(problems, fmt.Sprintf("%s (%s): %v", f.key, f.env, err))
+		}
+		cfg.sources[f.key] = source
+	}
+	problems = append(problems, cfg.loadSecrets(fields)...)
+	for key := range fileValues {
+		problems = append(problems, fmt.Sprintf("%s: clave desconocida en %s", key, *configFile))
+	}
+
+	cfg.normalize()
+	problems = append(problems, cfg.validate()...)
+	if len(problems) > 0 {
+		slices.Sort(problems)
+		return cfg, flags.Args(), &ValidationError{Problems: problems}
+	}
+
+	return cfg, flags.Args(), nil
+}
+
+// takeFileValue extrae del archivo el valor del campo. Los campos de tipo mapa
+// se escriben en el archivo como una sección y se convierten a "clave=valor,...".
+func takeFileValue(fileValues map[string]string, f configField) (string, bool) {
+	if f.value.Kind() != reflect.Map {
+		value, ok := fileValues[f.key]
+		delete(fileValues, f.key)
+		return value, ok
+	}
+
+	var entries []string
+	for key, value := range fileValues {
+		if name, ok := strings.CutPrefix(key, f.key+"."); ok {
+			entries = append(entries, name+"="+value)
+			delete(fileValues, key)
+		}
+	}
+	slices.Sort(entries)
+	return strings.Join(entries, ","), len(entries) > 0
+}
+
+// loadSecrets obtiene las credenciales del SecretProvider configurado (file o
+// vault), que tiene prioridad sobre el archivo y el entorno pero no sobre los
+// flags. Los secretos que el proveedor no tiene conservan su valor.
+func (c *Config) loadSecrets(fields []configField) []string {
+	if c.Secrets.Provider == SecretsEnv {
+		return nil
+	}
+	provider, err := NewSecretProvider(c.Secrets)
+	if err != nil {
+		// validate informa el proveedor inválido
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var problems []string
+	for _, f := range fields {
+		if !f.credential || c.sources[f.key] == SourceFlag {
+			continue
+		}
+		value, err := provider.GetSecret(ctx, f.env)
+		if errors.Is(err, ErrSecretNotFound) {
+			continue
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s (%s): %v", f.key, f.env, err))
+			continue
+		}
+		f.value.SetString(value)
+		c.sources[f.key] = SourceSecret
+		c.Database.rotating[f.env] = provider
+	}
+	return problems
+}
+
+// Settings devuelve los valores efectivos de la configuración con los secretos redactados.
+func (c *Config) Settings() []Setting {
+	var settings []Setting
+	for _, f := range c.fields() {
+		value := formatValue(f.value)
+		if f.secret && value != "" {
+			value = redacted
+		}
+		settings = append(settings, Setting{
+			Key:    f.key,
+			Env:    f.env,
+			Flag:   f.flag(),
+			Value:  value,
+			Source: c.sources[f.key],
+		})
+	}
+	return settings
+}
+
+// normalize completa los valores que dependen de otros.
+func (c *Config) normalize() {
+	c.Env = strings.ToLower(strings.TrimSpace(c.Env))
+	c.LogLevel = strings.ToLower(strings.TrimSpace(c.LogLevel))
+	c.Database.Driver = strings.ToLower(strings.TrimSpace(c.Database.Driver))
+	c.Database.SSLMode = normalizeSSLMode(c.Database.SSLMode)
+
+	if c.Database.Port == "" {
+		c.Database.Port = "3306"
+		if c.Database.Driver == DriverPostgres {
+			c.Database.Port = "5432"
+		}
+	}
+}
+
+// requiredFields son los campos que fuera de desarrollo (en staging y
+// production) deben configurarse explícitamente, según el motor: no se aceptan
+// los valores por defecto como DB_USER=root o DB_PASSWORD=password.
+var requiredFields = map[string][]string{
+	DriverMySQL:    {"database.host", "database.user", "database.password", "database.name"},
+	DriverPostgres: {"database.host", "database.user", "database.password", "database.name"},
+	DriverSQLite:   {"database.path"},
+}
+
+// devHS256Secret es el secreto HS256 de ejemplo de .env.example. Solo se
+// acepta en development y test: cualquiera puede firmar tokens con él.
+const devHS256Secret = "dev-only-hs256-secret-change-me-0123456789"
+
+// validate devuelve todos los problemas de la configuración ya cargada.
+func (c *Config) validate() []string {
+	var problems []string
+	envs := make(map[string]string)
+	for _, f := range c.fields() {
+		envs[f.key] = f.env
+	}
+	problem := func(key, format string, args ...any) {
+		problems = append(problems, fmt.Sprintf("%s (%s): %s", key, envs[key], fmt.Sprintf(format, args...)))
+	}
+
+	switch c.Secrets.Provider {
+	case SecretsEnv, SecretsFile, SecretsVault:
+	default:
+		problem("secrets.provider", "%q no es un proveedor válido (env, file o vault)", c.Secrets.Provider)
+	}
+
+	switch c.LogLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		problem("log_level", "%q no es un nivel válido (debug, info, warn o error)", c.LogLevel)
+	}
+
+	if c.Auth.Enabled {
+		if c.Auth.HS256Secret == "" && c.Auth.PublicKeyFile == "" && c.Auth.JWKSFile == "" && c.Auth.SigningKeyFile == "" {
+			problem("auth.enabled", "requiere AUTH_HS256_SECRET, AUTH_PUBLIC_KEY_FILE, AUTH_JWKS_FILE o AUTH_SIGNING_KEY_FILE (o AUTH_ENABLED=false)")
+		}
+		if c.Auth.HS256Secret != "" && len(c.Auth.HS256Secret) < 32 {
+			problem("auth.hs256_secret", "debe tener al menos 32 caracteres")
+		}
+		if c.Auth.HS256Secret == devHS256Secret && c.Env != EnvDevelopment && c.Env != EnvTest {
+			problem("auth.hs256_secret", "el secreto de ejemplo de .env.example solo se admite en development y test")
+		}
+		if c.Auth.ClockSkew < 0 {
+			problem("auth.clock_skew", "no puede ser negativo")
+		}
+	} else if c.Env == EnvProduction {
+		problem("auth.enabled", "la autenticación no se puede desactivar en production")
+	}
+	if c.Auth.TokenTTL <= 0 {
+		problem("auth.token_ttl", "debe ser mayor que 0")
+	}
+
+	if c.Password.MinLength < 8 {
+		problem("password.min_length", "debe ser al menos 8")
+	}
+	if c.Password.MaxLength < c.Password.MinLength || c.Password.MaxLength > 1024 {
+		problem("password.max_length", "debe estar entre min_length y 1024")
+	}
+	if c.Password.Argon2Memory < 8192 || c.Password.Argon2Memory > 4194304 {
+		problem("password.argon2_memory", "debe estar entre 8192 y 4194304 KiB")
+	}
+	if c.Password.Argon2Iterations < 1 || c.Password.Argon2Iterations > 100 {
+		problem("password.argon2_iterations", "debe estar entre 1 y 100")
+	}
+	if c.Password.Argon2Parallelism < 1 || c.Password.Argon2Parallelism > 255 {
+		problem("password.argon2_parallelism", "debe estar entre 1 y 255")
+	}
+	if c.Password.MaxConcurrent < 1 {
+		problem("password.max_concurrent", "debe ser mayor que 0")
+	}
+
+	if c.RateLimit.RPS < 0 {
+		problem("rate_limit.rps", "no puede ser negativo")
+	}
+	if c.RateLimit.RPS > 0 && c.RateLimit.Burst <= 0 {
+		problem("rate_limit.burst", "debe ser mayor que 0 si el límite está activo")
+	}
+
+	switch c.Env {
+	case EnvDevelopment, EnvTest, EnvStaging, EnvProduction:
+	default:
+		problem("env", "%q no es un entorno válido (development, test, staging o production)", c.Env)
+	}
+
+	if !validPort(c.Server.Port) {
+		problem("server.port", "%q no es un puerto válido", c.Server.Port)
+	}
+
+	switch c.Database.Driver {
+	case DriverMySQL, DriverPostgres:
+		if !validPort(c.Database.Port) {
+			problem("database.port", "%q no es un puerto válido", c.Database.Port)
+		}
+		switch c.Database.SSLMode {
+		case SSLDisable, SSLPreferred, SSLRequired, SSLVerifyCA, SSLVerifyFull:
+		default:
+			problem("database.ssl_mode", "%q no es válido (disable, preferred, required, verify-ca o verify-full)", c.Database.SSLMode)
+		}
+		if c.Database.SSLMode == SSLVerifyCA && c.Database.SSLCA == "" {
+			problem("database.ssl_ca", "obligatorio con ssl_mode=verify-ca")
+		}
+		if (c.Database.SSLCert == "") != (c.Database.SSLKey == "") {
+			problem("database.ssl_cert", "ssl_cert y ssl_key deben indicarse juntos")
+		}
+	case DriverSQLite:
+		if c.Database.Path == "" {
+			problem("database.path", "obligatorio con driver=sqlite")
+		}
+	default:
+		problem("database.driver", "%q no es un motor soportado (mysql, sqlite o postgres)", c.Database.Driver)
+	}
+
+	if c.Server.MaxHeaderBytes <= 0 {
+		problem("server.max_header_bytes", "debe ser mayor que 0")
+	}
+	for key, n := range map[string]int{
+		"database.max_open_conns": c.Database.MaxOpenConns,
+		"database.max_idle_conns": c.Database.MaxIdleConns,
+	} {
+		if n < 0 {
+			problem(key, "no puede ser negativo")
+		}
+	}
+	for route, timeout := range c.Server.RouteReadTimeouts {
+		if timeout < 0 {
+			problem("server.route_read_timeouts", "%s no puede ser negativo", route)
+		}
+	}
+	for route, timeout := range c.Server.RouteWriteTimeouts {
+		if timeout < 0 {
+			problem("server.route_write_timeouts", "%s no puede ser negativo", route)
+		}
+	}
+
+	for key, timeout := range map[string]time.Duration{
+		"server.read_timeout":         c.Server.ReadTimeout,
+		"server.read_header_timeout":  c.Server.ReadHeaderTimeout,
+		"server.write_timeout":        c.Server.WriteTimeout,
+		"server.idle_timeout":         c.Server.IdleTimeout,
+		"server.shutdown_timeout":     c.Server.ShutdownTimeout,
+		"database.conn_max_lifetime":  c.Database.ConnMaxLifetime,
+		"database.conn_max_idle_time": c.Database.ConnMaxIdleTime,
+		"database.read_timeout":       c.Database.ReadTimeout,
+		"database.write_timeout":      c.Database.WriteTimeout,
+		"database.bulk_timeout":       c.Database.BulkTimeout,
+		"database.export_timeout":     c.Database.ExportTimeout,
+		"purge.retention":             c.Purge.Retention,
+	} {
+		if timeout < 0 {
+			problem(key, "no puede ser negativo")
+		}
+	}
+	if c.Database.ConnectMaxWait > 0 && (c.Database.ConnectBackoff <= 0 || c.Database.ConnectMaxBackoff < c.Database.ConnectBackoff) {
+		problem("database.connect_backoff", "debe ser mayor que 0 y no mayor que connect_max_backoff")
+	}
+	if c.Purge.Retention > 0 && c.Purge.Interval <= 0 {
+		problem("purge.interval", "debe ser mayor que 0 si la purga está activa")
+	}
+
+	if c.Env == EnvStaging || c.Env == EnvProduction {
+		if c.Auth.Enabled && (c.Auth.Issuer == "" || c.Auth.Audience == "") {
+			problem("auth.issuer", "AUTH_ISSUER y AUTH_AUDIENCE son obligatorios en %s", c.Env)
+		}
+		for _, key := range requiredFields[c.Database.Driver] {
+			if c.sources[key] == SourceDefault {
+				problem(key, "obligatorio en %s", c.Env)
+			}
+		}
+	}
+
+	return problems
+}
+
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n <= 65535
+}
+
+// configField describe un campo de la configuración a partir de sus tags:
+//
+//	key:     nombre dentro de su sección en el archivo (server.port)
+//	env:     variable de entorno; el flag se deriva de ella (SERVER_PORT -> -server-port)
+//	default: valor por defecto
+//	secret:     "true" para redactarlo en config print
+//	credential: "true" si se puede obtener del SecretProvider y rotar
+//	reload:     "true" si se puede cambiar sin reiniciar (ver Watcher)
+//	usage:      descripción del flag
+type configField struct {
+	key        string
+	env        string
+	def        string
+	usage      string
+	secret     bool
+	credential bool
+	reload     bool
+	value      reflect.Value
+}
+
+func (f configField) flag() string {
+	return strings.ReplaceAll(strings.ToLower(f.env), "_", "-")
+}
+
+// fields recorre la configuración y devuelve sus campos en orden de declaración.
+func (c *Config) fields() []configField {
+	return collectFields(reflect.ValueOf(c).Elem(), "")
+}
+
+func collectFields(v reflect.Value, prefix string) []configField {
+	var fields []configField
+	for i := 0; i < v.NumField(); i++ {
+		sf := v.Type().Field(i)
+		key, ok := sf.Tag.Lookup("key")
+		if !ok {
+			continue
+		}
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if _, isLeaf := sf.Tag.Lookup("env"); !isLeaf && sf.Type.Kind() == reflect.Struct {
+			fields = append(fields, collectFields(v.Field(i), key)...)
+			continue
+		}
+		fields = append(fields, configField{
+			key:        key,
+			env:        sf.Tag.Get("env"),
+			def:        sf.Tag.Get("default"),
+			usage:      sf.Tag.Get("usage"),
+			secret:     sf.Tag.Get("secret") == "true",
+			credential: sf.Tag.Get("credential") == "true",
+			reload:     sf.Tag.Get("reload") == "true",
+			value:      v.Field(i),
+		})
+	}
+	return fields
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setValue convierte raw al tipo del campo. Las listas se escriben separadas por comas.
+func setValue(v reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+
+	switch {
+	case v.Type() == durationType:
+		if raw == "" {
+			v.SetInt(0)
+			return nil
+		}
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("%q no es una duración válida (por ejemplo 30s, 5m, 720h)", raw)
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(raw)
+	case v.Kind() == reflect.Int:
+		if raw == "" {
+			v.SetInt(0)
+			return nil
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("%q no es un número entero", raw)
+		}
+		v.SetInt(int64(n))
+	case v.Kind() == reflect.Bool:
+		if raw == "" {
+			v.SetBool(false)
+			return nil
+		}
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%q no es un booleano (true o false)", raw)
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		entries := reflect.MakeMap(v.Type())
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			name, value, ok := strings.Cut(item, "=")
+			if !ok {
+				return fmt.Errorf("%q debe tener el formato clave=valor", item)
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := setValue(elem, value); err != nil {
+				return fmt.Errorf("%s: %w", strings.TrimSpace(name), err)
+			}
+			entries.SetMapIndex(reflect.ValueOf(strings.TrimSpace(name)), elem)
+		}
+		v.Set(entries)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("tipo no soportado: %s", v.Type())
+	}
+	return nil
+}
+
+// formatValue es la inversa de setValue.
+func formatValue(v reflect.Value) string {
+	switch {
+	case v.Type() == durationType:
+		return time.Duration(v.Int()).String()
+	case v.Kind() == reflect.Slice:
+		return strings.Join(v.Interface().([]string), ",")
+	case v.Kind() == reflect.Map:
+		var entries []string
+		for iter := v.MapRange(); iter.Next(); {
+			entries = append(entries, iter.Key().String()+"="+formatValue(iter.Value()))
+		}
+		slices.Sort(entries)
+		return strings.Join(entries, ",")
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// readConfigFile lee un archivo YAML o TOML y lo aplana a claves
+// "seccion.campo". Sin archivo devuelve un mapa vacío.
+func readConfigFile(filename string) (map[string]string, error) {
+	values := make(map[string]string)
+	if filename == "" {
+		return values, nil
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer el archivo de configuración: %w", err)
+	}
+
+	var doc map[string]any
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &doc)
+	case ".toml":
+		err = toml.Unmarshal(content, &doc)
+	default:
+		return nil, fmt.Errorf("formato de archivo de configuración no soportado: %s (use .yaml, .yml o .toml)", filename)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo interpretar %s: %w", filename, err)
+	}
+
+	flattenConfig(doc, "", values)
+	return values, nil
+}
+
+func flattenConfig(doc map[string]any, prefix string, values map[string]string) {
+	for name, value := range doc {
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		switch value := value.(type) {
+		case map[string]any:
+			flattenConfig(value, key, values)
+		case []any:
+			items := make([]string, len(value))
+			for i, item := range value {
+				items[i] = fmt.Sprint(item)
+			}
+			values[key] = strings.Join(items, ",")
+		case nil:
+			values[key] = ""
+		default:
+			values[key] = fmt.Sprint(value)
+		}
+	}
+}
+
+// IsHelp indica si err corresponde a -h/-help, que ya imprimió la ayuda.
+func IsHelp(err error) bool {
+	return errors.Is(err, flag.ErrHelp)
+}
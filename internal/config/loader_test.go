@@ -0,0 +1,201 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  any
+		raw     string
+		want    any
+		wantErr string
+	}{
+		{name: "texto", target: new(string), raw: "  hola ", want: "hola"},
+		{name: "entero", target: new(int), raw: "42", want: 42},
+		{name: "entero vacío", target: new(int), raw: "", want: 0},
+		{name: "entero inválido", target: new(int), raw: "4x", wantErr: "no es un número entero"},
+		{name: "booleano", target: new(bool), raw: "true", want: true},
+		{name: "booleano numérico", target: new(bool), raw: "0", want: false},
+		{name: "booleano inválido", target: new(bool), raw: "sí", wantErr: "no es un booleano"},
+		{name: "duración", target: new(time.Duration), raw: "1m30s", want: 90 * time.Second},
+		{name: "duración vacía", target: new(time.Duration), raw: "", want: time.Duration(0)},
+		{name: "duración sin unidad", target: new(time.Duration), raw: "30", wantErr: "no es una duración válida"},
+		{name: "lista", target: new([]string), raw: "a, b,,c ", want: []string{"a", "b", "c"}},
+		{name: "lista vacía", target: new([]string), raw: "", want: []string(nil)},
+		{
+			name:   "mapa de duraciones",
+			target: new(map[string]time.Duration),
+			raw:    "users.export=0, users.import=5m",
+			want:   map[string]time.Duration{"users.export": 0, "users.import": 5 * time.Minute},
+		},
+		{name: "mapa de booleanos", target: new(Features), raw: "users.import=false", want: Features{"users.import": false}},
+		{name: "mapa sin igual", target: new(map[string]time.Duration), raw: "users.import", wantErr: "clave=valor"},
+		{name: "mapa con valor inválido", target: new(map[string]time.Duration), raw: "users.import=5", wantErr: "users.import: "},
+		{name: "tipo no soportado", target: new(float64), raw: "1.5", wantErr: "tipo no soportado"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := reflect.ValueOf(tt.target).Elem()
+			err := setValue(v, tt.raw)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("setValue(%q) = %v, se esperaba un error con %q", tt.raw, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("setValue(%q): %v", tt.raw, err)
+			}
+			if got := v.Interface(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("setValue(%q) = %#v, se esperaba %#v", tt.raw, got, tt.want)
+			}
+			if err := setValue(v, formatValue(v)); err != nil || !reflect.DeepEqual(v.Interface(), tt.want) {
+				t.Errorf("formatValue no es la inversa de setValue: %q", formatValue(v))
+			}
+		})
+	}
+}
+
+// defaultConfig devuelve la configuración por defecto con un secreto HS256,
+// que es lo mínimo que necesita para ser válida.
+func defaultConfig(t *testing.T) *Config {
+	t.Helper()
+
+	cfg := &Config{sources: make(map[string]string)}
+	for _, f := range cfg.fields() {
+		if err := setValue(f.value, f.def); err != nil {
+			t.Fatalf("%s: %v", f.key, err)
+		}
+		cfg.sources[f.key] = SourceDefault
+	}
+	cfg.Auth.HS256Secret = strings.Repeat("s", 32)
+	cfg.normalize()
+	return cfg
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *Config)
+		want   []string
+	}{
+		{name: "por defecto", modify: func(cfg *Config) {}},
+		{
+			name:   "entorno desconocido",
+			modify: func(cfg *Config) { cfg.Env = "qa" },
+			want:   []string{"env (APP_ENV)"},
+		},
+		{
+			name:   "puerto inválido",
+			modify: func(cfg *Config) { cfg.Server.Port = "70000" },
+			want:   []string{"server.port (SERVER_PORT)"},
+		},
+		{
+			name: "varios problemas a la vez",
+			modify: func(cfg *Config) {
+				cfg.LogLevel = "trace"
+				cfg.Database.Driver = "oracle"
+				cfg.Server.ReadTimeout = -time.Second
+			},
+			want: []string{"log_level (LOG_LEVEL)", "database.driver (DB_DRIVER)", "server.read_timeout (SERVER_READ_TIMEOUT)"},
+		},
+		{
+			name:   "sqlite sin ruta",
+			modify: func(cfg *Config) { cfg.Database.Driver = DriverSQLite; cfg.Database.Path = "" },
+			want:   []string{"database.path (DB_PATH)"},
+		},
+		{
+			name: "verify-ca sin CA",
+			modify: func(cfg *Config) {
+				cfg.Database.SSLMode = SSLVerifyCA
+				cfg.Database.SSLCert = "cert.pem"
+			},
+			want: []string{"database.ssl_ca", "database.ssl_cert"},
+		},
+		{
+			name:   "timeout de ruta negativo",
+			modify: func(cfg *Config) { cfg.Server.RouteWriteTimeouts["users.import"] = -time.Minute },
+			want:   []string{"server.route_write_timeouts"},
+		},
+		{
+			name:   "timeout de lectura de ruta negativo",
+			modify: func(cfg *Config) { cfg.Server.RouteReadTimeouts["users.import"] = -time.Minute },
+			want:   []string{"server.route_read_timeouts"},
+		},
+		{
+			name:   "secreto HS256 corto",
+			modify: func(cfg *Config) { cfg.Auth.HS256Secret = "corto" },
+			want:   []string{"auth.hs256_secret"},
+		},
+		{
+			name:   "secreto HS256 de ejemplo en development",
+			modify: func(cfg *Config) { cfg.Auth.HS256Secret = devHS256Secret },
+		},
+		{
+			name: "secreto HS256 de ejemplo en staging",
+			modify: func(cfg *Config) {
+				cfg.Env = EnvStaging
+				cfg.Auth.HS256Secret = devHS256Secret
+			},
+			want: []string{"auth.hs256_secret", "auth.issuer", "database.host", "database.user", "database.password", "database.name"},
+		},
+		{
+			name:   "autenticación sin claves",
+			modify: func(cfg *Config) { cfg.Auth.HS256Secret = "" },
+			want:   []string{"auth.enabled"},
+		},
+		{
+			name: "production sin autenticación",
+			modify: func(cfg *Config) {
+				cfg.Env = EnvProduction
+				cfg.Auth.Enabled = false
+			},
+			want: []string{"auth.enabled", "database.host", "database.user", "database.password", "database.name"},
+		},
+		{
+			name: "staging con la base de datos configurada",
+			modify: func(cfg *Config) {
+				cfg.Env = EnvStaging
+				cfg.Auth.Issuer = "https://auth.example.com"
+				cfg.Auth.Audience = "pt-brm"
+				for _, key := range requiredFields[DriverMySQL] {
+					cfg.sources[key] = SourceEnv
+				}
+			},
+		},
+		{
+			name:   "contraseña máxima menor que la mínima",
+			modify: func(cfg *Config) { cfg.Password.MinLength = 20; cfg.Password.MaxLength = 10 },
+			want:   []string{"password.max_length"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultConfig(t)
+			tt.modify(cfg)
+
+			problems := cfg.validate()
+			if len(problems) != len(tt.want) {
+				t.Fatalf("validate() = %q, se esperaban %d problemas", problems, len(tt.want))
+			}
+			for _, want := range tt.want {
+				found := false
+				for _, p := range problems {
+					if strings.HasPrefix(p, want) {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("validate() = %q, falta %q", problems, want)
+				}
+			}
+		})
+	}
+}
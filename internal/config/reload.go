@@ -0,0 +1,157 @@
+package config
+
+import (
+	"context"
+	"log"
+	"maps"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// configPollInterval es la frecuencia con la que se revisa si cambió el archivo de configuración.
+const configPollInterval = 2 * time.Second
+
+// Observer es un componente que aplica la configuración nueva tras una recarga.
+// Solo cambian los campos marcados con reload:"true"; los demás conservan el
+// valor con el que arrancó el proceso.
+type Observer interface {
+	ConfigChanged(cfg *Config)
+}
+
+// ObserverFunc adapta una función a Observer.
+type ObserverFunc func(cfg *Config)
+
+func (f ObserverFunc) ConfigChanged(cfg *Config) {
+	f(cfg)
+}
+
+// Watcher recarga la configuración al recibir SIGHUP o cuando cambia el
+// archivo de configuración, y avisa a los observers suscritos.
+type Watcher struct {
+	args []string
+
+	mu        sync.Mutex
+	current   *Config
+	observers []Observer
+}
+
+// NewWatcher crea un Watcher para la configuración cargada con args (los
+// mismos argumentos que recibió LoadConfig).
+func NewWatcher(cfg *Config, args []string) *Watcher {
+	return &Watcher{args: args, current: cfg}
+}
+
+// Current devuelve la configuración vigente.
+func (w *Watcher) Current() *Config {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.current
+}
+
+// Subscribe registra un observer para las próximas recargas.
+func (w *Watcher) Subscribe(observer Observer) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.observers = append(w.observers, observer)
+}
+
+// Reload vuelve a cargar la configuración y aplica los cambios permitidos en
+// caliente. Si la configuración nueva es inválida se mantiene la actual.
+func (w *Watcher) Reload() error {
+	next, _, err := LoadConfig(w.args)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	merged, changed := w.current.merge(next)
+	w.current = merged
+	observers := slices.Clone(w.observers)
+	w.mu.Unlock()
+
+	if len(changed) == 0 {
+		log.Println("Configuración recargada sin cambios")
+		return nil
+	}
+	log.Printf("Configuración recargada, cambios aplicados: %v", changed)
+	for _, observer := range observers {
+		observer.ConfigChanged(merged)
+	}
+	return nil
+}
+
+// Watch recarga la configuración con cada señal de hup o cambio del archivo de
+// configuración, hasta que ctx se cancele. hup debe registrarse con
+// signal.Notify antes de arrancar, para no perder un SIGHUP (que terminaría el
+// proceso) mientras tanto.
+func (w *Watcher) Watch(ctx context.Context, hup <-chan os.Signal) {
+	// Sin archivo de configuración solo se recarga con SIGHUP
+	var poll <-chan time.Time
+	file := w.Current().file
+	modTime := fileModTime(file)
+	if file != "" {
+		ticker := time.NewTicker(configPollInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			log.Println("SIGHUP recibido, recargando la configuración...")
+		case <-poll:
+			current := fileModTime(file)
+			if current.Equal(modTime) {
+				continue
+			}
+			modTime = current
+			log.Printf("%s cambió, recargando la configuración...", file)
+		}
+
+		if err := w.Reload(); err != nil {
+			log.Printf("No se pudo recargar la configuración, se mantiene la actual: %v", err)
+		}
+	}
+}
+
+func fileModTime(filename string) time.Time {
+	if filename == "" {
+		return time.Time{}
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// merge devuelve una copia de c con los campos recargables tomados de next y
+// las claves que cambiaron. Los cambios en campos que requieren reiniciar solo
+// se advierten.
+func (c *Config) merge(next *Config) (*Config, []string) {
+	merged := *c
+	merged.sources = maps.Clone(c.sources)
+
+	var changed []string
+	current, updated, target := c.fields(), next.fields(), merged.fields()
+	for i, f := range current {
+		if formatValue(f.value) == formatValue(updated[i].value) {
+			continue
+		}
+		if !f.reload {
+			// Las credenciales rotables se vuelven a leer al abrir conexiones
+			if _, rotating := c.Database.rotating[f.env]; !rotating {
+				log.Printf("Warning: %s (%s) cambió pero requiere reiniciar; se mantiene el valor actual", f.key, f.env)
+			}
+			continue
+		}
+		target[i].value.Set(updated[i].value)
+		merged.sources[f.key] = next.sources[f.key]
+		changed = append(changed, f.key)
+	}
+	return &merged, changed
+}
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Proveedores de secretos (SECRETS_PROVIDER).
+const (
+	SecretsEnv   = "env"
+	SecretsFile  = "file"
+	SecretsVault = "vault"
+)
+
+// ErrSecretNotFound indica que el proveedor no tiene el secreto pedido.
+var ErrSecretNotFound = errors.New("secreto no encontrado")
+
+// SecretProvider obtiene secretos por nombre (el de su variable de entorno,
+// por ejemplo DB_PASSWORD). Se consulta al cargar la configuración y cada vez
+// que se abre una conexión nueva con la base de datos, para poder rotar las
+// credenciales sin reiniciar.
+type SecretProvider interface {
+	GetSecret(ctx context.Context, name string) (string, error)
+}
+
+// SecretsConfig selecciona el proveedor de las credenciales de la base de datos.
+type SecretsConfig struct {
+	Provider string `key:"provider" env:"SECRETS_PROVIDER" default:"env" usage:"proveedor de secretos: env, file o vault"`
+	// Dir es el directorio de FileSecretProvider (secretos de Docker/Kubernetes).
+	Dir        string        `key:"dir" env:"SECRETS_DIR" default:"/run/secrets" usage:"directorio de los secretos del proveedor file"`
+	VaultAddr  string        `key:"vault_addr" env:"VAULT_ADDR" default:"http://127.0.0.1:8200" usage:"dirección del servidor compatible con Vault"`
+	VaultToken string        `key:"vault_token" env:"VAULT_TOKEN" secret:"true" usage:"token de Vault"`
+	VaultPath  string        `key:"vault_path" env:"VAULT_SECRET_PATH" default:"secret/data/pt-brm" usage:"ruta del secreto en Vault (KV v1 o v2)"`
+	CacheTTL   time.Duration `key:"cache_ttl" env:"SECRETS_CACHE_TTL" default:"1m" usage:"tiempo que se reutiliza un secreto leído de Vault"`
+}
+
+// NewSecretProvider crea el proveedor configurado.
+func NewSecretProvider(cfg SecretsConfig) (SecretProvider, error) {
+	switch cfg.Provider {
+	case SecretsEnv:
+		return EnvSecretProvider{}, nil
+	case SecretsFile:
+		return FileSecretProvider{Dir: cfg.Dir}, nil
+	case SecretsVault:
+		return NewVaultSecretProvider(cfg.VaultAddr, cfg.VaultToken, cfg.VaultPath, cfg.CacheTTL), nil
+	}
+	return nil, fmt.Errorf("proveedor de secretos no soportado: %q (env, file o vault)", cfg.Provider)
+}
+
+// EnvSecretProvider lee el secreto de la variable NAME o, si no está
+// definida, del archivo indicado en NAME_FILE.
+type EnvSecretProvider struct{}
+
+func (EnvSecretProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	if value := os.Getenv(name); value != "" {
+		return value, nil
+	}
+	if path := os.Getenv(name + "_FILE"); path != "" {
+		return readSecretFile(path)
+	}
+	return "", ErrSecretNotFound
+}
+
+// FileSecretProvider lee cada secreto de un archivo en Dir, llamado como la
+// variable (DB_PASSWORD) o en minúsculas (db_password).
+type FileSecretProvider struct {
+	Dir string
+}
+
+func (p FileSecretProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	for _, filename := range []string{name, strings.ToLower(name)} {
+		value, err := readSecretFile(filepath.Join(p.Dir, filename))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		return value, err
+	}
+	return "", ErrSecretNotFound
+}
+
+// readSecretFile lee un secreto de un archivo, sin el salto de línea final.
+func readSecretFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("no se pudo leer el secreto %s: %w", path, err)
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+// VaultSecretProvider lee los secretos de un servidor compatible con la API
+// HTTP de Vault: GET <Addr>/v1/<Path> con el header X-Vault-Token. Acepta
+// motores KV v1 ({"data": {...}}) y v2 ({"data": {"data": {...}}}). La
+// respuesta se reutiliza durante cacheTTL.
+type VaultSecretProvider struct {
+	addr     string
+	token    string
+	path     string
+	cacheTTL time.Duration
+	client   *http.Client
+
+	mu        sync.Mutex
+	cached    map[string]string
+	fetchedAt time.Time
+}
+
+func NewVaultSecretProvider(addr, token, path string, cacheTTL time.Duration) *VaultSecretProvider {
+	return &VaultSecretProvider{
+		addr:     strings.TrimRight(addr, "/"),
+		token:    token,
+		path:     strings.Trim(path, "/"),
+		cacheTTL: cacheTTL,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *VaultSecretProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	secrets, err := p.secrets(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, key := range []string{name, strings.ToLower(name)} {
+		if value, ok := secrets[key]; ok {
+			return value, nil
+		}
+	}
+	return "", ErrSecretNotFound
+}
+
+func (p *VaultSecretProvider) secrets(ctx context.Context) (map[string]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cached != nil && time.Since(p.fetchedAt) < p.cacheTTL {
+		return p.cached, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.addr+"/v1/"+p.path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo crear la solicitud a Vault: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo consultar Vault: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("el secreto %s no existe en Vault", p.path)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault respondió %s al leer %s", resp.Status, p.path)
+	}
+
+	var body struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("respuesta de Vault inválida: %w", err)
+	}
+
+	data := body.Data
+	// KV v2 anida los valores en data.data junto a data.metadata
+	if inner, ok := data["data"].(map[string]any); ok {
+		if _, ok := data["metadata"]; ok {
+			data = inner
+		}
+	}
+
+	secrets := make(map[string]string, len(data))
+	for key, value := range data {
+		if s, ok := value.(string); ok {
+			secrets[key] = s
+		} else {
+			secrets[key] = fmt.Sprint(value)
+		}
+	}
+
+	p.cached = secrets
+	p.fetchedAt = time.Now()
+	return secrets, nil
+}
@@ -0,0 +1,50 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"pt-brm/internal/config"
+	"strconv"
+	"strings"
+)
+
+// Rebind adapta una consulta escrita con marcadores "?" al motor de la
+// conexión; Postgres usa marcadores numerados ($1, $2, ...). Las consultas
+// no deben contener "?" dentro de literales.
+func (db *DB) Rebind(query string) string {
+	if db.driver != config.DriverPostgres || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// rebindExecutor aplica Rebind a cada consulta antes de delegarla.
+type rebindExecutor struct {
+	db   *DB
+	exec Executor
+}
+
+func (e rebindExecutor) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return e.exec.ExecContext(ctx, e.db.Rebind(query), args...)
+}
+
+func (e rebindExecutor) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return e.exec.QueryContext(ctx, e.db.Rebind(query), args...)
+}
+
+func (e rebindExecutor) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return e.exec.QueryRowContext(ctx, e.db.Rebind(query), args...)
+}
@@ -1,56 +1,130 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"pt-brm/internal/config"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
 )
 
+// ErrUnavailable indica que no se pudo contactar la base de datos; a
+// diferencia de una configuración inválida, vale la pena reintentar.
+var ErrUnavailable = errors.New("base de datos no disponible")
+
 type DB struct {
 	*sql.DB
+	driver   string
+	sslMode  string
+	timeouts map[Operation]time.Duration
 }
 
+// Operation clasifica las consultas para aplicarles un tiempo máximo distinto.
+type Operation int
+
+const (
+	OpRead Operation = iota
+	OpWrite
+	OpBulk
+	OpExport
+)
+
 // Crea una nueva conexión a la base de datos utilizando la configuración proporcionada.
-func NewConnection(cfg config.DatabaseConfig) (*DB, error) {
-	// Crea la conneción de la base de datos
-	db, err := sql.Open("mysql", cfg.GetDSN())
-	if err != nil {
-		return nil, fmt.Errorf("error al abrir la conexión con la base de datos: %w", err)
+func NewConnection(ctx context.Context, cfg config.DatabaseConfig) (*DB, error) {
+	switch cfg.Driver {
+	case config.DriverMySQL, config.DriverSQLite, config.DriverPostgres:
+	default:
+		return nil, fmt.Errorf("motor de base de datos no soportado: %q", cfg.Driver)
+	}
+
+	if err := configureTLS(cfg); err != nil {
+		return nil, fmt.Errorf("configuración TLS inválida: %w", err)
+	}
+
+	// Crea la conneción de la base de datos; si las credenciales vienen de un
+	// secreto, cada conexión nueva las vuelve a leer
+	var db *sql.DB
+	if cfg.RotatesCredentials() && cfg.Driver != config.DriverSQLite {
+		connector, err := newRotatingConnector(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("error al abrir la conexión con la base de datos: %w", err)
+		}
+		db = sql.OpenDB(connector)
+	} else {
+		var err error
+		db, err = sql.Open(cfg.Driver, cfg.GetDSN())
+		if err != nil {
+			return nil, fmt.Errorf("error al abrir la conexión con la base de datos: %w", err)
+		}
 	}
 
 	// Configurar pool de conexiones
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(25)
-	db.SetConnMaxLifetime(5 * time.Minute)
+	if cfg.Driver == config.DriverSQLite {
+		// SQLite admite un solo escritor; una única conexión evita errores
+		// SQLITE_BUSY y mantiene viva una base ":memory:"
+		db.SetMaxOpenConns(1)
+		db.SetMaxIdleConns(1)
+		db.SetConnMaxLifetime(0)
+	} else {
+		db.SetMaxOpenConns(cfg.MaxOpenConns)
+		db.SetMaxIdleConns(cfg.MaxIdleConns)
+		db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+		db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	}
 
 	// Verificar conexión
-	if err := db.Ping(); err != nil {
-		return nil, fmt.Errorf("error al comprobar Ping con la base de datos: %w", err)
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%w: error al comprobar Ping con la base de datos: %w", ErrUnavailable, err)
 	}
 
-	return &DB{db}, nil
+	return &DB{
+		DB:      db,
+		driver:  cfg.Driver,
+		sslMode: sslMode(cfg),
+		timeouts: map[Operation]time.Duration{
+			OpRead:   cfg.ReadTimeout,
+			OpWrite:  cfg.WriteTimeout,
+			OpBulk:   cfg.BulkTimeout,
+			OpExport: cfg.ExportTimeout,
+		},
+	}, nil
 }
 
-func (db *DB) Migrate() error {
-	query := `
-	CREATE TABLE IF NOT EXISTS users (
-		id INT AUTO_INCREMENT PRIMARY KEY,
-		name VARCHAR(80) NOT NULL,
-		email VARCHAR(100) NOT NULL UNIQUE,
-		age INT NOT NULL,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-		INDEX idx_email (email),
-		INDEX idx_created_at (created_at)
-	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
-	`
-
-	if _, err := db.Exec(query); err != nil {
-		return fmt.Errorf("no se pudo crear la tabla users: %w", err)
-	}
-
-	return nil
+// Driver devuelve el motor de la conexión (config.DriverMySQL, DriverSQLite o DriverPostgres).
+func (db *DB) Driver() string {
+	return db.driver
+}
+
+// sslMode devuelve el modo TLS efectivo; SQLite nunca usa TLS.
+func sslMode(cfg config.DatabaseConfig) string {
+	if cfg.Driver == config.DriverSQLite {
+		return config.SSLDisable
+	}
+	return cfg.SSLMode
+}
+
+// WithTimeout deriva de ctx un contexto con el tiempo máximo configurado para
+// el tipo de operación. Si no hay límite configurado solo se hereda ctx.
+func (db *DB) WithTimeout(ctx context.Context, op Operation) (context.Context, context.CancelFunc) {
+	if timeout := db.timeouts[op]; timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// ConfigChanged aplica los tamaños del pool tras una recarga de la configuración.
+func (db *DB) ConfigChanged(cfg *config.Config) {
+	if db.driver == config.DriverSQLite {
+		return
+	}
+	db.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.Database.ConnMaxIdleTime)
 }
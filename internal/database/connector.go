@@ -0,0 +1,56 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"pt-brm/internal/config"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+// openConnector crea el conector del driver para un DSN.
+var openConnector = map[string]func(dsn string) (driver.Connector, error){
+	config.DriverMySQL: mysql.MySQLDriver{}.OpenConnector,
+	config.DriverPostgres: func(dsn string) (driver.Connector, error) {
+		return pq.NewConnector(dsn)
+	},
+}
+
+// rotatingConnector vuelve a leer las credenciales cada vez que el pool abre
+// una conexión nueva, de modo que una contraseña rotada en el SecretProvider
+// se usa sin reiniciar. Las conexiones ya abiertas siguen con la anterior
+// hasta que las renueva ConnMaxLifetime o ConnMaxIdleTime.
+type rotatingConnector struct {
+	cfg config.DatabaseConfig
+	drv driver.Driver
+}
+
+func newRotatingConnector(cfg config.DatabaseConfig) (*rotatingConnector, error) {
+	open, ok := openConnector[cfg.Driver]
+	if !ok {
+		return nil, fmt.Errorf("el motor %s no admite rotación de credenciales", cfg.Driver)
+	}
+	connector, err := open(cfg.GetDSN())
+	if err != nil {
+		return nil, err
+	}
+	return &rotatingConnector{cfg: cfg, drv: connector.Driver()}, nil
+}
+
+func (c *rotatingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	dsn, err := c.cfg.CurrentDSN(ctx)
+	if err != nil {
+		return nil, err
+	}
+	connector, err := openConnector[c.cfg.Driver](dsn)
+	if err != nil {
+		return nil, err
+	}
+	return connector.Connect(ctx)
+}
+
+func (c *rotatingConnector) Driver() driver.Driver {
+	return c.drv
+}
@@ -0,0 +1,449 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/fs"
+	"path"
+	"pt-brm/internal/config"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cada motor tiene sus propias migraciones en migrations/<driver>, con las
+// mismas versiones en todos.
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+// Nombre del lock de MySQL que evita que dos instancias migren al mismo tiempo.
+const (
+	migrationLockName    = "pt-brm:schema_migrations"
+	migrationLockTimeout = 30 // segundos
+)
+
+// Clave del advisory lock equivalente en Postgres.
+var migrationLockKey = int64(crc32.ChecksumIEEE([]byte(migrationLockName)))
+
+// Formato de los archivos: 0001_descripcion.up.sql / 0001_descripcion.down.sql
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration es una versión del esquema con sus scripts de subida y bajada.
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// MigrationStatus indica si una migración está aplicada en la base de datos.
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+	// Modified es true si el archivo cambió después de haber sido aplicado.
+	Modified bool
+}
+
+// Migrator aplica y revierte las migraciones embebidas en el binario.
+type Migrator struct {
+	db         *DB
+	migrations []Migration
+}
+
+type appliedMigration struct {
+	checksum  string
+	appliedAt time.Time
+}
+
+// NewMigrator carga las migraciones embebidas y las ordena por versión.
+func NewMigrator(db *DB) (*Migrator, error) {
+	migrations, err := loadMigrations(migrationFiles, path.Join("migrations", db.driver))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Migrate aplica todas las migraciones pendientes.
+func (db *DB) Migrate(ctx context.Context) error {
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	return migrator.Up(ctx)
+}
+
+// Up aplica todas las migraciones pendientes.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.To(ctx, m.latestVersion())
+}
+
+// Down revierte la última migración aplicada.
+func (m *Migrator) Down(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			if _, ok := applied[m.migrations[i].Version]; ok {
+				return m.revert(ctx, conn, m.migrations[i])
+			}
+		}
+
+		return nil
+	})
+}
+
+// To lleva el esquema exactamente a la versión indicada, subiendo o bajando según corresponda.
+func (m *Migrator) To(ctx context.Context, version int) error {
+	if version != 0 && m.find(version) == nil {
+		return fmt.Errorf("la migración %d no existe", version)
+	}
+
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		if err := m.verify(applied); err != nil {
+			return err
+		}
+
+		// Revertir en orden inverso todo lo que esté por encima de la versión objetivo
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; ok && migration.Version > version {
+				if err := m.revert(ctx, conn, migration); err != nil {
+					return err
+				}
+			}
+		}
+
+		// Aplicar en orden todo lo pendiente hasta la versión objetivo
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; !ok && migration.Version <= version {
+				if err := m.apply(ctx, conn, migration); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// Status devuelve el estado de cada migración conocida.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			status := MigrationStatus{Migration: migration}
+			if record, ok := applied[migration.Version]; ok {
+				status.Applied = true
+				status.AppliedAt = record.appliedAt
+				status.Modified = record.checksum != migration.Checksum
+			}
+			statuses = append(statuses, status)
+		}
+
+		return nil
+	})
+
+	return statuses, err
+}
+
+// withLock obtiene el lock de migraciones en una conexión dedicada y ejecuta fn con ella.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("no se pudo obtener una conexión para migrar: %w", err)
+	}
+	defer conn.Close()
+
+	switch m.db.driver {
+	case config.DriverMySQL:
+		var acquired sql.NullInt64
+		if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", migrationLockName, migrationLockTimeout).Scan(&acquired); err != nil {
+			return fmt.Errorf("no se pudo obtener el lock de migraciones: %w", err)
+		}
+		if acquired.Int64 != 1 {
+			return errors.New("otra instancia está ejecutando migraciones")
+		}
+		// Liberar aunque ctx se haya cancelado: la conexión vuelve al pool con la sesión abierta
+		defer conn.ExecContext(context.WithoutCancel(ctx), "DO RELEASE_LOCK(?)", migrationLockName)
+	case config.DriverPostgres:
+		lockCtx, cancel := context.WithTimeout(ctx, migrationLockTimeout*time.Second)
+		defer cancel()
+		if _, err := conn.ExecContext(lockCtx, "SELECT pg_advisory_lock($1)", migrationLockKey); err != nil {
+			return fmt.Errorf("no se pudo obtener el lock de migraciones: %w", err)
+		}
+		defer conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", migrationLockKey)
+	default:
+		// En SQLite el pool tiene una única conexión, que ya serializa las migraciones
+	}
+
+	if err := m.ensureTable(ctx, conn); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+// DDL de la tabla schema_migrations en cada motor.
+var migrationsTableDDL = map[string]string{
+	config.DriverMySQL: `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		checksum CHAR(64) NOT NULL,
+		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`,
+	config.DriverPostgres: `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		checksum CHAR(64) NOT NULL,
+		applied_at TIMESTAMPTZ DEFAULT NOW()
+	);
+	`,
+	config.DriverSQLite: `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		checksum TEXT NOT NULL,
+		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	`,
+}
+
+func (m *Migrator) ensureTable(ctx context.Context, conn *sql.Conn) error {
+	if _, err := conn.ExecContext(ctx, migrationsTableDDL[m.db.driver]); err != nil {
+		return fmt.Errorf("no se pudo crear la tabla schema_migrations: %w", err)
+	}
+
+	return nil
+}
+
+// applied devuelve las versiones registradas en schema_migrations.
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[int]appliedMigration, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, checksum, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo consultar las migraciones aplicadas: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]appliedMigration)
+	for rows.Next() {
+		var version int
+		var record appliedMigration
+		if err := rows.Scan(&version, &record.checksum, &record.appliedAt); err != nil {
+			return nil, fmt.Errorf("no se pudo escanear la migración: %w", err)
+		}
+		applied[version] = record
+	}
+
+	return applied, rows.Err()
+}
+
+// verify falla si alguna migración aplicada fue modificada o ya no existe en el binario.
+func (m *Migrator) verify(applied map[int]appliedMigration) error {
+	for version, record := range applied {
+		migration := m.find(version)
+		if migration == nil {
+			return fmt.Errorf("la migración %d está aplicada pero no existe en el binario", version)
+		}
+		if migration.Checksum != record.checksum {
+			return fmt.Errorf("la migración %d (%s) fue modificada después de aplicarse", version, migration.Name)
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	return m.inTx(ctx, conn, func(exec Executor) error {
+		if err := execScript(ctx, exec, migration.Up); err != nil {
+			return fmt.Errorf("no se pudo aplicar la migración %d (%s): %w", migration.Version, migration.Name, err)
+		}
+
+		_, err := exec.ExecContext(ctx,
+			m.db.Rebind("INSERT INTO schema_migrations (version, name, checksum) VALUES (?, ?, ?)"),
+			migration.Version, migration.Name, migration.Checksum,
+		)
+		if err != nil {
+			return fmt.Errorf("no se pudo registrar la migración %d: %w", migration.Version, err)
+		}
+
+		return nil
+	})
+}
+
+func (m *Migrator) revert(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	if migration.Down == "" {
+		return fmt.Errorf("la migración %d (%s) no tiene script down", migration.Version, migration.Name)
+	}
+
+	return m.inTx(ctx, conn, func(exec Executor) error {
+		if err := execScript(ctx, exec, migration.Down); err != nil {
+			return fmt.Errorf("no se pudo revertir la migración %d (%s): %w", migration.Version, migration.Name, err)
+		}
+
+		if _, err := exec.ExecContext(ctx, m.db.Rebind("DELETE FROM schema_migrations WHERE version = ?"), migration.Version); err != nil {
+			return fmt.Errorf("no se pudo eliminar el registro de la migración %d: %w", migration.Version, err)
+		}
+
+		return nil
+	})
+}
+
+// inTx ejecuta fn en una transacción de conn, para que una migración que
+// falla a mitad de camino no quede aplicada a medias ni sin registrar. En
+// MySQL cada sentencia DDL confirma por sí misma, así que fn usa conn directamente.
+func (m *Migrator) inTx(ctx context.Context, conn *sql.Conn, fn func(exec Executor) error) error {
+	if m.db.driver == config.DriverMySQL {
+		return fn(conn)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("no se pudo iniciar la transacción de la migración: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("no se pudo confirmar la migración: %w", err)
+	}
+	return nil
+}
+
+func (m *Migrator) find(version int) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) latestVersion() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// execScript ejecuta cada sentencia del script por separado, ya que el driver
+// no permite varias sentencias en un mismo Exec sin multiStatements.
+func execScript(ctx context.Context, exec Executor, script string) error {
+	for _, statement := range splitStatements(script) {
+		if _, err := exec.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitStatements separa un script en sentencias usando los ";" al final de línea.
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+
+		current.WriteString(line)
+		current.WriteString("\n")
+
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+
+	return statements
+}
+
+// loadMigrations lee los archivos .up.sql/.down.sql de dir y los agrupa por versión.
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudieron leer las migraciones: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("nombre de migración inválido: %s", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo leer %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("la versión %d tiene nombres distintos: %s y %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+			sum := sha256.Sum256(content)
+			migration.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("la migración %d (%s) no tiene script up", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
@@ -0,0 +1,117 @@
+package database
+
+import (
+	"context"
+	"testing"
+)
+
+// appliedVersions devuelve las versiones aplicadas según Status.
+func appliedVersions(t *testing.T, m *Migrator) []int {
+	t.Helper()
+
+	statuses, err := m.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+
+	var versions []int
+	for _, status := range statuses {
+		if status.Applied {
+			versions = append(versions, status.Version)
+		}
+	}
+	return versions
+}
+
+func tableExists(t *testing.T, db *DB, name string) bool {
+	t.Helper()
+
+	var n int
+	err := db.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&n)
+	if err != nil {
+		t.Fatalf("sqlite_master: %v", err)
+	}
+	return n > 0
+}
+
+func TestMigratorRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	m, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	latest := m.latestVersion()
+
+	if got := appliedVersions(t, m); len(got) != 0 {
+		t.Fatalf("aplicadas antes de migrar: %v", got)
+	}
+
+	if err := m.Up(ctx); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if got := appliedVersions(t, m); len(got) != len(m.migrations) || got[len(got)-1] != latest {
+		t.Fatalf("aplicadas tras Up: %v", got)
+	}
+	if !tableExists(t, db, "users") {
+		t.Fatal("Up no creó la tabla users")
+	}
+
+	if err := m.Down(ctx); err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if got := appliedVersions(t, m); len(got) != len(m.migrations)-1 || got[len(got)-1] == latest {
+		t.Fatalf("aplicadas tras Down: %v", got)
+	}
+
+	if err := m.To(ctx, 0); err != nil {
+		t.Fatalf("To(0): %v", err)
+	}
+	if got := appliedVersions(t, m); len(got) != 0 {
+		t.Fatalf("aplicadas tras To(0): %v", got)
+	}
+	if tableExists(t, db, "users") {
+		t.Fatal("To(0) no eliminó la tabla users")
+	}
+
+	// El ciclo completo se puede repetir
+	if err := m.Up(ctx); err != nil {
+		t.Fatalf("Up tras To(0): %v", err)
+	}
+	if got := appliedVersions(t, m); len(got) != len(m.migrations) {
+		t.Fatalf("aplicadas tras el segundo Up: %v", got)
+	}
+}
+
+// TestMigratorFailedMigrationIsAtomic comprueba que una migración que falla a
+// mitad de camino no deje sentencias aplicadas ni quede registrada.
+func TestMigratorFailedMigrationIsAtomic(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	m := &Migrator{db: db, migrations: []Migration{{
+		Version:  1,
+		Name:     "falla_a_mitad",
+		Up:       "CREATE TABLE parcial (id INTEGER);\nCREATE TABLE parcial (id INTEGER);\n",
+		Down:     "DROP TABLE parcial;\n",
+		Checksum: "x",
+	}}}
+
+	if err := m.Up(ctx); err == nil {
+		t.Fatal("Up no falló")
+	}
+	if tableExists(t, db, "parcial") {
+		t.Error("la primera sentencia de la migración fallida quedó aplicada")
+	}
+	if got := appliedVersions(t, m); len(got) != 0 {
+		t.Errorf("la migración fallida quedó registrada: %v", got)
+	}
+
+	// Corregida, la migración se aplica sin problemas
+	m.migrations[0].Up = "CREATE TABLE parcial (id INTEGER);\n"
+	if err := m.Up(ctx); err != nil {
+		t.Fatalf("Up tras corregir: %v", err)
+	}
+	if !tableExists(t, db, "parcial") {
+		t.Error("Up no creó la tabla")
+	}
+}
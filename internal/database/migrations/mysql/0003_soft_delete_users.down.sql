@@ -0,0 +1,6 @@
+DELETE FROM users WHERE deleted_at IS NOT NULL;
+ALTER TABLE users DROP INDEX idx_deleted_at;
+ALTER TABLE users DROP INDEX uq_users_active_email;
+ALTER TABLE users ADD UNIQUE INDEX email (email);
+ALTER TABLE users DROP COLUMN active_email;
+ALTER TABLE users DROP COLUMN deleted_at;
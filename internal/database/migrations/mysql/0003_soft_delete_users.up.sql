@@ -0,0 +1,8 @@
+-- Los usuarios eliminados conservan su fila con deleted_at; el email solo debe
+-- ser único entre los usuarios activos, así que la restricción se mueve a una
+-- columna generada que vale NULL para los eliminados (UNIQUE admite varios NULL).
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL AFTER updated_at;
+ALTER TABLE users ADD COLUMN active_email VARCHAR(100) GENERATED ALWAYS AS (IF(deleted_at IS NULL, email, NULL)) STORED;
+ALTER TABLE users DROP INDEX email;
+ALTER TABLE users ADD UNIQUE INDEX uq_users_active_email (active_email);
+ALTER TABLE users ADD INDEX idx_deleted_at (deleted_at);
@@ -0,0 +1,16 @@
+-- Solo se guarda el hash con sal del secreto (SHA-256 en hexadecimal); prefix
+-- identifica la clave al autenticar sin revelar el secreto.
+CREATE TABLE IF NOT EXISTS api_keys (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	name VARCHAR(80) NOT NULL,
+	prefix VARCHAR(16) NOT NULL,
+	salt CHAR(32) NOT NULL,
+	hash CHAR(64) NOT NULL,
+	scopes VARCHAR(255) NOT NULL,
+	expires_at TIMESTAMP NULL DEFAULT NULL,
+	last_used_at TIMESTAMP NULL DEFAULT NULL,
+	revoked_at TIMESTAMP NULL DEFAULT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	UNIQUE INDEX uq_api_keys_prefix (prefix)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
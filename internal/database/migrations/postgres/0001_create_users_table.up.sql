@@ -0,0 +1,13 @@
+-- Postgres compara el texto distinguiendo mayúsculas; el índice sobre
+-- LOWER(email) mantiene la unicidad sin distinguirlas, como en MySQL.
+CREATE TABLE IF NOT EXISTS users (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(80) NOT NULL,
+	email VARCHAR(100) NOT NULL,
+	age INT NOT NULL,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_key ON users (LOWER(email));
+CREATE INDEX IF NOT EXISTS idx_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_created_at ON users (created_at);
@@ -0,0 +1,6 @@
+-- El email solo debe ser único entre los usuarios activos; Postgres lo
+-- resuelve con un índice único parcial.
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMPTZ NULL DEFAULT NULL;
+DROP INDEX users_email_key;
+CREATE UNIQUE INDEX uq_users_active_email ON users (LOWER(email)) WHERE deleted_at IS NULL;
+CREATE INDEX idx_deleted_at ON users (deleted_at);
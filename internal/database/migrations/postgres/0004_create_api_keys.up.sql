@@ -0,0 +1,16 @@
+-- Solo se guarda el hash con sal del secreto (SHA-256 en hexadecimal); prefix
+-- identifica la clave al autenticar sin revelar el secreto.
+CREATE TABLE IF NOT EXISTS api_keys (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(80) NOT NULL,
+	prefix VARCHAR(16) NOT NULL,
+	salt CHAR(32) NOT NULL,
+	hash CHAR(64) NOT NULL,
+	scopes VARCHAR(255) NOT NULL,
+	expires_at TIMESTAMPTZ NULL DEFAULT NULL,
+	last_used_at TIMESTAMPTZ NULL DEFAULT NULL,
+	revoked_at TIMESTAMPTZ NULL DEFAULT NULL,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_api_keys_prefix ON api_keys (prefix);
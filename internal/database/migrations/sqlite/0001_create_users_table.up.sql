@@ -0,0 +1,13 @@
+-- Las columnas de texto usan NOCASE para comparar sin distinguir mayúsculas,
+-- igual que utf8mb4_unicode_ci en MySQL. Las fechas se guardan en UTC con el
+-- mismo formato con el que las escribe el driver, para poder compararlas como texto.
+CREATE TABLE IF NOT EXISTS users (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL COLLATE NOCASE CONSTRAINT name_length CHECK (length(name) <= 80),
+	email TEXT NOT NULL COLLATE NOCASE CONSTRAINT email_length CHECK (length(email) <= 100),
+	age INTEGER NOT NULL,
+	created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now')),
+	updated_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now'))
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_created_at ON users (created_at);
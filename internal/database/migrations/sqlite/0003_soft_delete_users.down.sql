@@ -0,0 +1,5 @@
+DELETE FROM users WHERE deleted_at IS NOT NULL;
+DROP INDEX idx_deleted_at;
+DROP INDEX uq_users_active_email;
+CREATE UNIQUE INDEX idx_users_email ON users (email);
+ALTER TABLE users DROP COLUMN deleted_at;
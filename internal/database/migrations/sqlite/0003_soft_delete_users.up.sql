@@ -0,0 +1,6 @@
+-- El email solo debe ser único entre los usuarios activos; SQLite lo resuelve
+-- con un índice único parcial.
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL;
+DROP INDEX idx_users_email;
+CREATE UNIQUE INDEX uq_users_active_email ON users (email) WHERE deleted_at IS NULL;
+CREATE INDEX idx_deleted_at ON users (deleted_at);
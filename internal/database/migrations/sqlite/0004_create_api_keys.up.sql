@@ -0,0 +1,16 @@
+-- Solo se guarda el hash con sal del secreto (SHA-256 en hexadecimal); prefix
+-- identifica la clave al autenticar sin revelar el secreto.
+CREATE TABLE IF NOT EXISTS api_keys (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL CONSTRAINT name_length CHECK (length(name) <= 80),
+	prefix TEXT NOT NULL,
+	salt TEXT NOT NULL,
+	hash TEXT NOT NULL,
+	scopes TEXT NOT NULL,
+	expires_at TIMESTAMP NULL DEFAULT NULL,
+	last_used_at TIMESTAMP NULL DEFAULT NULL,
+	revoked_at TIMESTAMP NULL DEFAULT NULL,
+	created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now')),
+	updated_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now'))
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_api_keys_prefix ON api_keys (prefix);
@@ -0,0 +1,3 @@
+-- Hash de la contraseña (argon2id o bcrypt heredado); NULL si el usuario no
+-- puede iniciar sesión.
+ALTER TABLE users ADD COLUMN password_hash TEXT NULL DEFAULT NULL;
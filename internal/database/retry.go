@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"pt-brm/internal/config"
+	"time"
+)
+
+// Connect abre la conexión con la base de datos reintentando con espera
+// exponencial y jitter mientras no esté disponible, hasta cfg.ConnectMaxWait.
+// Los errores de configuración (motor, TLS) no se reintentan.
+func Connect(ctx context.Context, cfg config.DatabaseConfig) (*DB, error) {
+	var db *DB
+	err := retry(ctx, cfg, "conectar con la base de datos", func(ctx context.Context) error {
+		var err error
+		db, err = NewConnection(ctx, cfg)
+		return err
+	}, func(err error) bool {
+		return errors.Is(err, ErrUnavailable)
+	})
+	return db, err
+}
+
+// MigrateWithRetry aplica las migraciones pendientes reintentando si falla
+// porque se perdió la conexión; un error en una migración no se reintenta.
+func (db *DB) MigrateWithRetry(ctx context.Context, cfg config.DatabaseConfig) error {
+	return retry(ctx, cfg, "aplicar las migraciones", db.Migrate, func(err error) bool {
+		return db.PingContext(ctx) != nil
+	})
+}
+
+// retry ejecuta fn hasta que termine sin error, el error no sea retryable o
+// se agote cfg.ConnectMaxWait.
+func retry(ctx context.Context, cfg config.DatabaseConfig, action string, fn func(ctx context.Context) error, retryable func(error) bool) error {
+	deadline := time.Now().Add(cfg.ConnectMaxWait)
+	delay := cfg.ConnectBackoff
+
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil || !retryable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		// Espera exponencial con jitter (entre delay/2 y delay) sin pasar el máximo
+		wait := delay/2 + rand.N(delay/2+1)
+		if remaining := time.Until(deadline); wait > remaining {
+			return fmt.Errorf("no se pudo %s tras %d intentos en %s: %w", action, attempt, cfg.ConnectMaxWait, err)
+		}
+		log.Printf("No se pudo %s (intento %d), reintentando en %s: %v", action, attempt, wait.Round(time.Millisecond), err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		delay = min(delay*2, cfg.ConnectMaxBackoff)
+	}
+}
@@ -0,0 +1,163 @@
+package database
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"pt-brm/internal/config"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// TLSStatus describe el cifrado negociado con la base de datos.
+type TLSStatus struct {
+	Mode    string `json:"mode"`
+	Enabled bool   `json:"enabled"`
+	Version string `json:"version,omitempty"`
+	Cipher  string `json:"cipher,omitempty"`
+}
+
+// configureTLS valida DB_SSL_MODE y los certificados configurados y, para
+// MySQL, registra la configuración TLS que referencia el DSN. Cualquier error
+// aquí impide arrancar: un modo verify-* que no se puede cumplir no debe
+// degradar a una conexión sin verificar.
+func configureTLS(cfg config.DatabaseConfig) error {
+	if cfg.Driver == config.DriverSQLite {
+		return nil
+	}
+
+	switch cfg.SSLMode {
+	case config.SSLDisable, config.SSLPreferred:
+		if cfg.SSLCA != "" || cfg.SSLCert != "" || cfg.SSLKey != "" {
+			return fmt.Errorf("DB_SSL_CA, DB_SSL_CERT y DB_SSL_KEY requieren DB_SSL_MODE=required, verify-ca o verify-full (actual: %q)", cfg.SSLMode)
+		}
+		return nil
+	case config.SSLRequired, config.SSLVerifyCA, config.SSLVerifyFull:
+	default:
+		return fmt.Errorf("DB_SSL_MODE no válido: %q (valores: disable, preferred, required, verify-ca, verify-full)", cfg.SSLMode)
+	}
+
+	tlsConfig, err := buildTLSConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	// lib/pq lee los certificados a partir del DSN; basta con validarlos
+	if cfg.Driver != config.DriverMySQL {
+		return nil
+	}
+	if err := mysql.RegisterTLSConfig(config.MySQLTLSConfigName, tlsConfig); err != nil {
+		return fmt.Errorf("no se pudo registrar la configuración TLS: %w", err)
+	}
+	return nil
+}
+
+// buildTLSConfig construye la configuración TLS para los modos required,
+// verify-ca y verify-full.
+func buildTLSConfig(cfg config.DatabaseConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if (cfg.SSLCert == "") != (cfg.SSLKey == "") {
+		return nil, errors.New("DB_SSL_CERT y DB_SSL_KEY deben indicarse juntos")
+	}
+	if cfg.SSLCert != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.SSLCert, cfg.SSLKey)
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo cargar el certificado de cliente: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	var roots *x509.CertPool
+	if cfg.SSLCA != "" {
+		pem, err := os.ReadFile(cfg.SSLCA)
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo leer DB_SSL_CA: %w", err)
+		}
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("DB_SSL_CA no contiene certificados PEM válidos: %s", cfg.SSLCA)
+		}
+	}
+
+	switch cfg.SSLMode {
+	case config.SSLRequired:
+		tlsConfig.InsecureSkipVerify = true
+	case config.SSLVerifyCA:
+		if roots == nil {
+			return nil, errors.New("DB_SSL_MODE=verify-ca requiere DB_SSL_CA")
+		}
+		// Se verifica la cadena contra la CA pero no el nombre del host
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 {
+				return errors.New("el servidor no presentó certificado")
+			}
+			intermediates := x509.NewCertPool()
+			for _, cert := range state.PeerCertificates[1:] {
+				intermediates.AddCert(cert)
+			}
+			_, err := state.PeerCertificates[0].Verify(x509.VerifyOptions{
+				Roots:         roots,
+				Intermediates: intermediates,
+			})
+			return err
+		}
+	case config.SSLVerifyFull:
+		if cfg.Host == "" {
+			return nil, errors.New("DB_SSL_MODE=verify-full requiere DB_HOST")
+		}
+		// Sin DB_SSL_CA se usan las CA del sistema
+		tlsConfig.RootCAs = roots
+		tlsConfig.ServerName = cfg.Host
+	}
+
+	return tlsConfig, nil
+}
+
+// TLSStatus consulta al servidor si la sesión actual está cifrada.
+func (db *DB) TLSStatus(ctx context.Context) (TLSStatus, error) {
+	status := TLSStatus{Mode: db.sslMode}
+
+	switch db.driver {
+	case config.DriverMySQL:
+		rows, err := db.QueryContext(ctx, "SHOW SESSION STATUS WHERE Variable_name IN ('Ssl_version', 'Ssl_cipher')")
+		if err != nil {
+			return status, fmt.Errorf("no se pudo consultar el estado TLS: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var name, value string
+			if err := rows.Scan(&name, &value); err != nil {
+				return status, fmt.Errorf("no se pudo leer el estado TLS: %w", err)
+			}
+			switch name {
+			case "Ssl_version":
+				status.Version = value
+			case "Ssl_cipher":
+				status.Cipher = value
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return status, fmt.Errorf("no se pudo leer el estado TLS: %w", err)
+		}
+		status.Enabled = status.Cipher != ""
+	case config.DriverPostgres:
+		var version, cipher sql.NullString
+		err := db.QueryRowContext(ctx,
+			"SELECT ssl, version, cipher FROM pg_stat_ssl WHERE pid = pg_backend_pid()",
+		).Scan(&status.Enabled, &version, &cipher)
+		if err != nil {
+			return status, fmt.Errorf("no se pudo consultar el estado TLS: %w", err)
+		}
+		status.Version = version.String
+		status.Cipher = cipher.String
+	}
+
+	return status, nil
+}
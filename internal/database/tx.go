@@ -0,0 +1,153 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"pt-brm/internal/config"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+// Reintentos de una transacción abortada por un deadlock o por agotar la espera de un lock.
+const (
+	txMaxAttempts = 3
+	txRetryDelay  = 20 * time.Millisecond
+)
+
+// Códigos de error que justifican reintentar la transacción completa.
+const (
+	errLockWaitTimeout = 1205
+	errDeadlock        = 1213
+
+	pgErrSerializationFailure = "40001"
+	pgErrDeadlockDetected     = "40P01"
+	pgErrLockNotAvailable     = "55P03"
+)
+
+// Executor es lo común entre *sql.DB y *sql.Tx para ejecutar consultas.
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type txKey struct{}
+
+// ambientTx es la transacción en curso guardada en el contexto.
+type ambientTx struct {
+	tx         *sql.Tx
+	savepoints int
+}
+
+// WithinTx ejecuta fn dentro de una transacción: confirma si fn termina sin
+// error y revierte en caso contrario. El ctx que recibe fn lleva la
+// transacción, de modo que los repositorios que lo usen participan de ella.
+//
+// Si ctx ya tiene una transacción, fn se ejecuta en un savepoint que se
+// revierte solo a sí mismo si fn falla. La transacción más externa se
+// reintenta completa ante un deadlock o un timeout de espera de lock.
+func (db *DB) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if current, ok := ctx.Value(txKey{}).(*ambientTx); ok {
+		return current.withinSavepoint(ctx, fn)
+	}
+
+	var err error
+	for attempt := 1; attempt <= txMaxAttempts; attempt++ {
+		err = db.runTx(ctx, fn)
+		if err == nil || !isRetryable(err) || attempt == txMaxAttempts {
+			return err
+		}
+
+		// Espera exponencial con jitter para que las transacciones en conflicto no choquen de nuevo
+		delay := txRetryDelay << (attempt - 1)
+		delay += rand.N(delay)
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+
+	return err
+}
+
+// Executor devuelve la transacción en curso en ctx o, si no hay, el pool.
+// Las consultas se escriben con marcadores "?" y se adaptan al motor.
+func (db *DB) Executor(ctx context.Context) Executor {
+	var exec Executor = db.DB
+	if current, ok := ctx.Value(txKey{}).(*ambientTx); ok {
+		exec = current.tx
+	}
+
+	if db.driver == config.DriverPostgres {
+		return rebindExecutor{db: db, exec: exec}
+	}
+	return exec
+}
+
+func (db *DB) runTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("no se pudo iniciar la transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txKey{}, &ambientTx{tx: tx})); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("no se pudo confirmar la transacción: %w", err)
+	}
+
+	return nil
+}
+
+func (a *ambientTx) withinSavepoint(ctx context.Context, fn func(ctx context.Context) error) error {
+	a.savepoints++
+	name := fmt.Sprintf("sp_%d", a.savepoints)
+
+	if _, err := a.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("no se pudo crear el savepoint: %w", err)
+	}
+
+	if err := fn(ctx); err != nil {
+		// Un deadlock ya revirtió la transacción completa: no queda savepoint al que volver
+		if !isRetryable(err) {
+			if _, rbErr := a.tx.ExecContext(context.WithoutCancel(ctx), "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+				return errors.Join(err, fmt.Errorf("no se pudo revertir el savepoint: %w", rbErr))
+			}
+		}
+		return err
+	}
+
+	if _, err := a.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("no se pudo liberar el savepoint: %w", err)
+	}
+
+	return nil
+}
+
+// isRetryable indica si err proviene de un deadlock o de un timeout de espera de lock.
+func isRetryable(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == errDeadlock || mysqlErr.Number == errLockWaitTimeout
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case pgErrSerializationFailure, pgErrDeadlockDetected, pgErrLockNotAvailable:
+			return true
+		}
+	}
+
+	return false
+}
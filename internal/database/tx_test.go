@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"pt-brm/internal/config"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+var errTest = errors.New("falla de prueba")
+
+// newTestDB abre una base SQLite en memoria con una tabla items(v).
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+
+	ctx := context.Background()
+	db, err := NewConnection(ctx, config.DatabaseConfig{Driver: config.DriverSQLite, Path: ":memory:"})
+	if err != nil {
+		t.Fatalf("NewConnection: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := db.ExecContext(ctx, "CREATE TABLE items (v INTEGER NOT NULL)"); err != nil {
+		t.Fatalf("CREATE TABLE: %v", err)
+	}
+	return db
+}
+
+func insertItem(t *testing.T, db *DB, ctx context.Context, v int) {
+	t.Helper()
+	if _, err := db.Executor(ctx).ExecContext(ctx, "INSERT INTO items (v) VALUES (?)", v); err != nil {
+		t.Fatalf("INSERT %d: %v", v, err)
+	}
+}
+
+func countItems(t *testing.T, db *DB) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM items").Scan(&n); err != nil {
+		t.Fatalf("COUNT: %v", err)
+	}
+	return n
+}
+
+func TestWithinTxSavepoints(t *testing.T) {
+	tests := []struct {
+		name      string
+		innerErr  error
+		outerErr  error
+		wantErr   error
+		wantItems int
+	}{
+		{name: "todo confirmado", wantItems: 2},
+		{name: "falla el savepoint", innerErr: errTest, wantItems: 1},
+		{name: "falla la transacción externa", outerErr: errTest, wantErr: errTest, wantItems: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+
+			err := db.WithinTx(context.Background(), func(ctx context.Context) error {
+				insertItem(t, db, ctx, 1)
+
+				innerErr := db.WithinTx(ctx, func(ctx context.Context) error {
+					insertItem(t, db, ctx, 2)
+					return tt.innerErr
+				})
+				if !errors.Is(innerErr, tt.innerErr) {
+					t.Errorf("savepoint = %v, se esperaba %v", innerErr, tt.innerErr)
+				}
+
+				return tt.outerErr
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("WithinTx = %v, se esperaba %v", err, tt.wantErr)
+			}
+			if got := countItems(t, db); got != tt.wantItems {
+				t.Errorf("filas = %d, se esperaban %d", got, tt.wantItems)
+			}
+		})
+	}
+}
+
+func TestWithinTxRetries(t *testing.T) {
+	deadlock := &mysql.MySQLError{Number: errDeadlock}
+	lockTimeout := &mysql.MySQLError{Number: errLockWaitTimeout}
+	serialization := &pq.Error{Code: pgErrSerializationFailure}
+
+	tests := []struct {
+		name      string
+		errs      []error // error de cada intento; después, nil
+		nested    bool    // el error ocurre dentro de un savepoint
+		wantCalls int
+		wantErr   error
+		wantItems int
+	}{
+		{name: "sin error", wantCalls: 1, wantItems: 1},
+		{name: "deadlock y éxito", errs: []error{deadlock}, wantCalls: 2, wantItems: 1},
+		{name: "timeout de lock y serialización", errs: []error{lockTimeout, serialization}, wantCalls: 3, wantItems: 1},
+		{name: "deadlock en un savepoint", errs: []error{deadlock}, nested: true, wantCalls: 2, wantItems: 1},
+		{name: "agota los intentos", errs: []error{deadlock, deadlock, deadlock}, wantCalls: txMaxAttempts, wantErr: deadlock},
+		{name: "error no reintentable", errs: []error{errTest}, wantCalls: 1, wantErr: errTest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+
+			calls := 0
+			err := db.WithinTx(context.Background(), func(ctx context.Context) error {
+				calls++
+				var attemptErr error
+				if calls <= len(tt.errs) {
+					attemptErr = tt.errs[calls-1]
+				}
+
+				insertItem(t, db, ctx, calls)
+				if tt.nested {
+					return db.WithinTx(ctx, func(ctx context.Context) error { return attemptErr })
+				}
+				return attemptErr
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("WithinTx = %v, se esperaba %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("fn se ejecutó %d veces, se esperaban %d", calls, tt.wantCalls)
+			}
+			// Los intentos fallidos se revierten: solo queda la fila del último exitoso
+			if got := countItems(t, db); got != tt.wantItems {
+				t.Errorf("filas = %d, se esperaban %d", got, tt.wantItems)
+			}
+		})
+	}
+}
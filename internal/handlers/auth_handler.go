@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"pt-brm/internal/auth"
+	"pt-brm/internal/models"
+	"pt-brm/internal/services"
+	"pt-brm/pkg/response"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// maxCredentialsBytes es el tamaño máximo del cuerpo de las solicitudes con
+// contraseñas; alcanza de sobra para PASSWORD_MAX_LENGTH.
+const maxCredentialsBytes = 16 << 10
+
+type AuthHandler struct {
+	credentialService services.CredentialService
+	issuer            *auth.Issuer
+}
+
+// NewAuthHandler crea el handler de credenciales; con issuer nil el inicio
+// de sesión responde 503 porque no hay con qué firmar los tokens.
+func NewAuthHandler(credentialService services.CredentialService, issuer *auth.Issuer) *AuthHandler {
+	return &AuthHandler{
+		credentialService: credentialService,
+		issuer:            issuer,
+	}
+}
+
+// POST /auth/login - Iniciar sesión con email y contraseña
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	if h.issuer == nil {
+		response.Error(w, http.StatusServiceUnavailable, "el inicio de sesión no está configurado")
+		return
+	}
+
+	var req models.LoginRequest
+	if !decodeCredentials(w, r, &req) {
+		return
+	}
+
+	user, err := h.credentialService.Login(r.Context(), &req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			response.Error(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		response.FromError(w, err)
+		return
+	}
+
+	token, expiresAt, err := h.issuer.Issue(strconv.Itoa(user.ID))
+	if err != nil {
+		response.FromError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	response.JSON(w, http.StatusOK, models.LoginResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(time.Until(expiresAt).Seconds()),
+		ExpiresAt:   expiresAt.UTC(),
+		User:        user,
+	})
+}
+
+// POST /users/{id}/password - Cambiar la contraseña. El propio usuario debe
+// enviar la actual y solo con users:write puede asignar la primera; quien tenga
+// users:admin puede asignar la de otro sin ella. Las API keys no pueden
+// cambiar contraseñas.
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	// Sin autenticación (AUTH_ENABLED=false) no hay claims y siempre se exige la contraseña actual
+	change := services.ChangeWithCurrentPassword
+	if claims, ok := auth.ClaimsFromContext(r.Context()); ok {
+		switch {
+		case claims.APIKeyID != 0:
+			response.Error(w, http.StatusForbidden, "las API keys no pueden cambiar contraseñas")
+			return
+		case claims.Subject == strconv.Itoa(id):
+			// Que el sub coincida no prueba la identidad (puede ser un token de
+			// otro emisor): sin users:write se exige la contraseña actual
+			if claims.HasScope(models.ScopeUsersWrite) || claims.HasScope(models.ScopeUsersAdmin) {
+				change = services.ChangeOwnPassword
+			}
+		case claims.HasScope(models.ScopeUsersAdmin):
+			change = services.ResetPassword
+		default:
+			response.Error(w, http.StatusForbidden, "se requiere el permiso "+models.ScopeUsersAdmin)
+			return
+		}
+	}
+
+	var req models.ChangePasswordRequest
+	if !decodeCredentials(w, r, &req) {
+		return
+	}
+
+	if err := h.credentialService.ChangePassword(r.Context(), id, &req, change); err != nil {
+		if errors.Is(err, services.ErrPasswordNotSet) {
+			response.Error(w, http.StatusForbidden, err.Error())
+			return
+		}
+		response.FromError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+// decodeCredentials decodifica el cuerpo en req con un límite de tamaño. Si
+// falla responde el error y devuelve false.
+func decodeCredentials(w http.ResponseWriter, r *http.Request, req any) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxCredentialsBytes)
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			response.Error(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("la solicitud supera el máximo de %d bytes", maxBytesErr.Limit))
+			return false
+		}
+		response.Error(w, http.StatusBadRequest, "Error al decodificar la solicitud")
+		return false
+	}
+	return true
+}
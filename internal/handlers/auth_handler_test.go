@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"pt-brm/internal/auth"
+	"pt-brm/internal/config"
+	"pt-brm/internal/models"
+	"pt-brm/internal/repositories"
+	"pt-brm/internal/services"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/mux"
+)
+
+// fakeCredentials registra cómo se pidió el cambio de contraseña.
+type fakeCredentials struct {
+	services.CredentialService
+	change *services.PasswordChange
+}
+
+func (f fakeCredentials) ChangePassword(ctx context.Context, id int, req *models.ChangePasswordRequest, change services.PasswordChange) error {
+	*f.change = change
+	return nil
+}
+
+func TestChangePasswordAuthorization(t *testing.T) {
+	token := func(subject, scope string) *auth.Claims {
+		return &auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: subject}, Scope: scope}
+	}
+
+	tests := []struct {
+		name       string
+		claims     *auth.Claims
+		wantStatus int
+		wantChange services.PasswordChange
+	}{
+		{name: "sin autenticación", wantStatus: http.StatusNoContent, wantChange: services.ChangeWithCurrentPassword},
+		{name: "el propio usuario", claims: token("7", "users:read"), wantStatus: http.StatusNoContent, wantChange: services.ChangeWithCurrentPassword},
+		{name: "el propio usuario con users:write", claims: token("7", "users:read users:write"), wantStatus: http.StatusNoContent, wantChange: services.ChangeOwnPassword},
+		{name: "administrador", claims: token("1", "users:read users:admin"), wantStatus: http.StatusNoContent, wantChange: services.ResetPassword},
+		{name: "otro usuario con users:write", claims: token("1", "users:read users:write"), wantStatus: http.StatusForbidden},
+		{name: "API key con users:write", claims: auth.NewAPIKeyClaims(3, []string{"users:write"}), wantStatus: http.StatusForbidden},
+		{name: "API key con users:admin", claims: auth.NewAPIKeyClaims(7, []string{"users:admin"}), wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			change := services.PasswordChange(-1)
+			handler := NewAuthHandler(fakeCredentials{change: &change}, nil)
+			router := mux.NewRouter()
+			router.HandleFunc("/users/{id}/password", handler.ChangePassword)
+
+			r := httptest.NewRequest("POST", "/users/7/password", strings.NewReader(`{"new_password":"una frase larga y segura"}`))
+			if tt.claims != nil {
+				r = r.WithContext(auth.WithClaims(r.Context(), tt.claims))
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, se esperaba %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusNoContent && change != tt.wantChange {
+				t.Errorf("change = %d, se esperaba %d", change, tt.wantChange)
+			}
+		})
+	}
+}
+
+func TestCredentialsBodyLimit(t *testing.T) {
+	change := services.PasswordChange(-1)
+	handler := NewAuthHandler(fakeCredentials{change: &change}, nil)
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{id}/password", handler.ChangePassword)
+
+	body := `{"new_password":"` + strings.Repeat("a", maxCredentialsBytes) + `"}`
+	r := httptest.NewRequest("POST", "/users/7/password", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, se esperaba %d", w.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+// TestChangePasswordForeignIssuer comprueba que un token de otro emisor cuyo
+// sub coincide con un id local no pueda asignar la primera contraseña.
+func TestChangePasswordForeignIssuer(t *testing.T) {
+	cfg := config.PasswordConfig{MinLength: 12, MaxLength: 64, Argon2Memory: 8192, Argon2Iterations: 1, Argon2Parallelism: 1, MaxConcurrent: 1}
+	policy, err := auth.NewPasswordPolicy(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	repo := repositories.NewMemoryUserRepository()
+	user, err := repo.Create(ctx, &models.User{Name: "Ana", Email: "ana@example.com", Age: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := NewAuthHandler(services.NewCredentialService(repo, auth.NewPasswordHasher(cfg), policy), nil)
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{id}/password", handler.ChangePassword)
+
+	claims := &auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Issuer: "https://idp.example.com", Subject: strconv.Itoa(user.ID)},
+		Scope:            "users:read",
+	}
+	r := httptest.NewRequest("POST", "/users/"+strconv.Itoa(user.ID)+"/password", strings.NewReader(`{"new_password":"una frase larga y segura"}`))
+	r = r.WithContext(auth.WithClaims(r.Context(), claims))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, se esperaba %d (%s)", w.Code, http.StatusForbidden, w.Body.String())
+	}
+	if hash, err := repo.GetPasswordHash(ctx, user.ID); err != nil || hash != "" {
+		t.Errorf("se asignó la contraseña (hash %q, error %v)", hash, err)
+	}
+}
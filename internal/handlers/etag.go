@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"pt-brm/internal/models"
+	"strconv"
+	"strings"
+)
+
+// ifMatchVersions lee las versiones aceptadas del header If-Match, que puede
+// ser una lista separada por comas. Devuelve nil cuando no hay condición
+// (header ausente o "*").
+func ifMatchVersions(r *http.Request) ([]int, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return nil, nil
+	}
+
+	var versions []int
+	for _, candidate := range strings.Split(value, ",") {
+		candidate = strings.TrimSpace(candidate)
+
+		// If-Match usa comparación fuerte: una etiqueta débil nunca coincide
+		if strings.HasPrefix(candidate, "W/") {
+			return nil, models.NewValidationError("weak_etag", "", "If-Match solo admite ETags fuertes")
+		}
+
+		version, err := strconv.Atoi(strings.Trim(candidate, `"`))
+		if err != nil || version <= 0 {
+			return nil, models.NewValidationError("invalid_if_match", "", "If-Match inválido")
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, nil
+}
+
+// noneMatch indica si alguna ETag de If-None-Match coincide con etag
+// (comparación débil, como exige RFC 9110 para GET).
+func noneMatch(r *http.Request, etag string) bool {
+	value := strings.TrimSpace(r.Header.Get("If-None-Match"))
+	if value == "" {
+		return false
+	}
+	if value == "*" {
+		return true
+	}
+
+	for _, candidate := range strings.Split(value, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestIfMatchVersions(t *testing.T) {
+	tests := []struct {
+		header  string
+		want    []int
+		wantErr bool
+	}{
+		{header: "", want: nil},
+		{header: "*", want: nil},
+		{header: `"3"`, want: []int{3}},
+		{header: `"3", "5"`, want: []int{3, 5}},
+		{header: `"3","5" ,"8"`, want: []int{3, 5, 8}},
+		{header: `W/"3"`, wantErr: true},
+		{header: `"3", W/"5"`, wantErr: true},
+		{header: `"abc"`, wantErr: true},
+		{header: `"0"`, wantErr: true},
+		{header: `"3",`, wantErr: true},
+		{header: `"3", *`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			r := httptest.NewRequest("PUT", "/api/v1/users/1", nil)
+			if tt.header != "" {
+				r.Header.Set("If-Match", tt.header)
+			}
+
+			got, err := ifMatchVersions(r)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ifMatchVersions(%q) = %v, se esperaba un error", tt.header, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ifMatchVersions(%q): %v", tt.header, err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ifMatchVersions(%q) = %v, se esperaba %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNoneMatch(t *testing.T) {
+	const etag = `"3"`
+
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{header: "", want: false},
+		{header: "*", want: true},
+		{header: `"3"`, want: true},
+		{header: `W/"3"`, want: true},
+		{header: `"2", "3"`, want: true},
+		{header: `"2",W/"3"`, want: true},
+		{header: `"2"`, want: false},
+		{header: `"33"`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/v1/users/1", nil)
+			if tt.header != "" {
+				r.Header.Set("If-None-Match", tt.header)
+			}
+
+			if got := noneMatch(r, etag); got != tt.want {
+				t.Errorf("noneMatch(%q) = %v, se esperaba %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
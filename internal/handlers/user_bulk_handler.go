@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"pt-brm/internal/models"
+	"pt-brm/pkg/response"
+)
+
+// POST /users/bulk?mode=atomic|best_effort - Crear usuarios en lote
+func (h *UserHandler) BulkCreateUsers(w http.ResponseWriter, r *http.Request) {
+	mode, err := models.ParseBulkMode(r.URL.Query().Get("mode"))
+	if err != nil {
+		response.FromError(w, err)
+		return
+	}
+
+	// Decodificar el cuerpo de la solicitud como un arreglo de CreateUserRequest
+	var reqs []models.CreateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+		response.Error(w, http.StatusBadRequest, "Error al decodificar la solicitud")
+		return
+	}
+
+	result, err := h.userService.BulkCreateUsers(r.Context(), reqs, mode)
+	if err != nil {
+		response.FromError(w, err)
+		return
+	}
+
+	response.JSON(w, bulkStatus(result, http.StatusCreated), result)
+}
+
+// PATCH /users/bulk?mode=atomic|best_effort - Actualizar parcialmente usuarios en lote
+func (h *UserHandler) BulkPatchUsers(w http.ResponseWriter, r *http.Request) {
+	mode, err := models.ParseBulkMode(r.URL.Query().Get("mode"))
+	if err != nil {
+		response.FromError(w, err)
+		return
+	}
+
+	// Decodificar el cuerpo de la solicitud como un arreglo de {id, version, changes}
+	var items []models.BulkPatchItem
+	if err := json.NewDecoder(r.Body).Decode(&items); err != nil {
+		response.Error(w, http.StatusBadRequest, "Error al decodificar la solicitud: "+err.Error())
+		return
+	}
+
+	result, err := h.userService.BulkPatchUsers(r.Context(), items, mode)
+	if err != nil {
+		response.FromError(w, err)
+		return
+	}
+
+	response.JSON(w, bulkStatus(result, http.StatusOK), result)
+}
+
+// DELETE /users/bulk?mode=atomic|best_effort - Eliminar usuarios en lote
+func (h *UserHandler) BulkDeleteUsers(w http.ResponseWriter, r *http.Request) {
+	mode, err := models.ParseBulkMode(r.URL.Query().Get("mode"))
+	if err != nil {
+		response.FromError(w, err)
+		return
+	}
+
+	// Decodificar el cuerpo de la solicitud como un arreglo de ids
+	var ids []int
+	if err := json.NewDecoder(r.Body).Decode(&ids); err != nil {
+		response.Error(w, http.StatusBadRequest, "Error al decodificar la solicitud")
+		return
+	}
+
+	result, err := h.userService.BulkDeleteUsers(r.Context(), ids, mode)
+	if err != nil {
+		response.FromError(w, err)
+		return
+	}
+
+	response.JSON(w, bulkStatus(result, http.StatusOK), result)
+}
+
+// bulkStatus elige el código HTTP de una operación masiva: éxito total usa
+// okStatus, un fallo en modo atómico 422 y un éxito parcial 207 Multi-Status.
+func bulkStatus(result *models.BulkResult, okStatus int) int {
+	switch {
+	case result.Failed == 0:
+		return okStatus
+	case result.Mode == models.BulkAtomic || result.Succeeded == 0:
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusMultiStatus
+	}
+}
@@ -2,22 +2,30 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
+	"mime"
 	"net/http"
+	"net/url"
 	"pt-brm/internal/models"
 	"pt-brm/internal/services"
 	"pt-brm/pkg/response"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
 type UserHandler struct {
 	userService services.UserService
+	// minPurgeAge es el older_than mínimo que acepta PurgeUsers (USERS_PURGE_RETENTION).
+	minPurgeAge time.Duration
 }
 
-func NewUserHandler(userService services.UserService) *UserHandler {
+func NewUserHandler(userService services.UserService, minPurgeAge time.Duration) *UserHandler {
 	return &UserHandler{
 		userService: userService,
+		minPurgeAge: minPurgeAge,
 	}
 }
 
@@ -31,24 +39,36 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.userService.CreateUser(&req)
+	user, err := h.userService.CreateUser(r.Context(), &req)
 	if err != nil {
-		response.Error(w, http.StatusBadRequest, err.Error())
+		response.FromError(w, err)
 		return
 	}
 
 	response.JSON(w, http.StatusCreated, user)
 }
 
-// GET /users - Obtener todos los usuarios
+// GET /users - Obtener los usuarios paginados (?limit=&offset= o ?limit=&cursor=),
+// con filtros como ?name~=ana&age>=18&email@=mail.com y orden ?sort=-age,name
 func (h *UserHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
-	users, err := h.userService.GetAllUsers()
+	params, err := parseListParams(r)
 	if err != nil {
-		response.Error(w, http.StatusInternalServerError, err.Error())
+		response.FromError(w, err)
 		return
 	}
 
-	response.JSON(w, http.StatusOK, users)
+	page, err := h.userService.ListUsers(r.Context(), params)
+	if err != nil {
+		response.FromError(w, err)
+		return
+	}
+
+	response.Paginated(w, http.StatusOK, page.Users, response.Pagination{
+		Total:      page.Total,
+		Limit:      page.Limit,
+		Offset:     page.Offset,
+		NextCursor: page.NextCursor,
+	})
 }
 
 // GET /users/{id} - Obtener usuario por ID
@@ -62,9 +82,16 @@ func (h *UserHandler) GetUserByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.userService.GetUserByID(id)
+	user, err := h.userService.GetUserByID(r.Context(), id)
 	if err != nil {
-		response.Error(w, http.StatusNotFound, err.Error())
+		response.FromError(w, err)
+		return
+	}
+
+	// Si el cliente ya tiene esta versión no hace falta reenviarla
+	w.Header().Set("ETag", user.ETag())
+	if noneMatch(r, user.ETag()) {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
 
@@ -76,9 +103,9 @@ func (h *UserHandler) GetByEmail(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	email := vars["email"]
 
-	user, err := h.userService.GetUserByEmail(email)
+	user, err := h.userService.GetUserByEmail(r.Context(), email)
 	if err != nil {
-		response.Error(w, http.StatusNotFound, err.Error())
+		response.FromError(w, err)
 		return
 	}
 
@@ -96,6 +123,12 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	versions, err := ifMatchVersions(r)
+	if err != nil {
+		response.FromError(w, err)
+		return
+	}
+
 	// Decodificar el cuerpo de la solicitud en la estructura UpdateUserRequest
 	var req models.UpdateUserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -103,12 +136,72 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.userService.UpdateUser(id, &req)
+	user, err := h.userService.UpdateUser(r.Context(), id, &req, versions)
+	if err != nil {
+		response.FromError(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", user.ETag())
+	response.JSON(w, http.StatusOK, user)
+}
+
+// PATCH /users/{id} - Actualizar parcialmente un usuario.
+// Acepta application/merge-patch+json (o application/json) y application/json-patch+json.
+func (h *UserHandler) PatchUser(w http.ResponseWriter, r *http.Request) {
+	// Obtener el ID del usuario de los parámetros de la ruta
+	vars := mux.Vars(r)
+	// Convertir el ID de string a int
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	versions, err := ifMatchVersions(r)
+	if err != nil {
+		response.FromError(w, err)
+		return
+	}
+
+	mediaType := "application/json"
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err = mime.ParseMediaType(contentType)
+		if err != nil {
+			response.Error(w, http.StatusUnsupportedMediaType, "Content-Type inválido")
+			return
+		}
+	}
+
+	// Decodificar el cuerpo según el formato de patch indicado
+	var patch models.UserPatch
+	switch mediaType {
+	case "application/json-patch+json":
+		var ops models.JSONPatch
+		if err := json.NewDecoder(r.Body).Decode(&ops); err != nil {
+			response.Error(w, http.StatusBadRequest, "Error al decodificar la solicitud")
+			return
+		}
+		patch = ops
+	case "application/merge-patch+json", "application/json":
+		var req models.PatchUserRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, http.StatusBadRequest, "Error al decodificar la solicitud: "+err.Error())
+			return
+		}
+		patch = &req
+	default:
+		response.Error(w, http.StatusUnsupportedMediaType, "Content-Type no soportado: "+mediaType)
+		return
+	}
+
+	user, err := h.userService.PatchUser(r.Context(), id, patch, versions)
 	if err != nil {
-		response.Error(w, http.StatusBadRequest, err.Error())
+		response.FromError(w, err)
 		return
 	}
 
+	w.Header().Set("ETag", user.ETag())
 	response.JSON(w, http.StatusOK, user)
 }
 
@@ -123,10 +216,159 @@ func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.userService.DeleteUser(id); err != nil {
-		response.Error(w, http.StatusNotFound, err.Error())
+	versions, err := ifMatchVersions(r)
+	if err != nil {
+		response.FromError(w, err)
+		return
+	}
+
+	if err := h.userService.DeleteUser(r.Context(), id, versions); err != nil {
+		response.FromError(w, err)
 		return
 	}
 
 	response.JSON(w, http.StatusNoContent, nil)
 }
+
+// POST /users/{id}/restore - Restaurar un usuario eliminado
+func (h *UserHandler) RestoreUser(w http.ResponseWriter, r *http.Request) {
+	// Obtener el ID del usuario de los parámetros de la ruta
+	vars := mux.Vars(r)
+	// Convertir el ID de string a int
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	user, err := h.userService.RestoreUser(r.Context(), id)
+	if err != nil {
+		response.FromError(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", user.ETag())
+	response.JSON(w, http.StatusOK, user)
+}
+
+// POST /users/purge?older_than=720h - Eliminar definitivamente los usuarios
+// que llevan más tiempo eliminados que older_than
+func (h *UserHandler) PurgeUsers(w http.ResponseWriter, r *http.Request) {
+	retention, err := time.ParseDuration(r.URL.Query().Get("older_than"))
+	if err != nil || retention < 0 {
+		response.Error(w, http.StatusBadRequest, "older_than inválido, use una duración como 720h")
+		return
+	}
+	// No se puede purgar antes que el job, para que un error no borre usuarios recién eliminados
+	if retention < h.minPurgeAge {
+		response.Error(w, http.StatusBadRequest, fmt.Sprintf("older_than debe ser al menos %s (USERS_PURGE_RETENTION)", h.minPurgeAge))
+		return
+	}
+
+	purged, err := h.userService.PurgeDeletedUsers(r.Context(), retention)
+	if err != nil {
+		response.FromError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]int64{"purged": purged})
+}
+
+// parseListParams lee los parámetros de paginación de la query string.
+func parseListParams(r *http.Request) (*models.UserListParams, error) {
+	query := r.URL.Query()
+	params := &models.UserListParams{}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, models.NewValidationError("invalid_limit", "limit", "limit inválido")
+		}
+		params.Limit = limit
+	}
+
+	if value := query.Get("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, models.NewValidationError("invalid_offset", "offset", "offset inválido")
+		}
+		params.Offset = offset
+	}
+
+	if value := query.Get("cursor"); value != "" {
+		cursor, err := models.DecodeCursor(value)
+		if err != nil {
+			return nil, err
+		}
+		params.Cursor = cursor
+	}
+
+	if value := query.Get("include_deleted"); value != "" {
+		includeDeleted, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, models.NewValidationError("invalid_include_deleted", "include_deleted", "include_deleted inválido")
+		}
+		params.IncludeDeleted = includeDeleted
+	}
+
+	if value := query.Get("sort"); value != "" {
+		sort, err := models.ParseSort(value)
+		if err != nil {
+			return nil, err
+		}
+		params.Sort = sort
+	}
+
+	filters, err := parseFilters(r.URL.RawQuery)
+	if err != nil {
+		return nil, err
+	}
+	params.Filters = filters
+
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+// Parámetros de la query string que no son filtros.
+var reservedListParams = map[string]bool{
+	"limit":           true,
+	"offset":          true,
+	"cursor":          true,
+	"sort":            true,
+	"include_deleted": true,
+	"format":          true,
+}
+
+// parseFilters interpreta cada término de la query string que no sea un
+// parámetro reservado como una expresión de filtro (campo, operador, valor).
+// Se lee la query sin procesar porque url.Values no conserva operadores como ">=".
+func parseFilters(rawQuery string) ([]models.Filter, error) {
+	var filters []models.Filter
+
+	for _, term := range strings.Split(rawQuery, "&") {
+		if term == "" {
+			continue
+		}
+
+		expr, err := url.QueryUnescape(term)
+		if err != nil {
+			return nil, models.NewValidationError("invalid_filter", "", fmt.Sprintf("filtro inválido: %q", term))
+		}
+
+		name, _, _ := strings.Cut(expr, "=")
+		if reservedListParams[name] {
+			continue
+		}
+
+		filter, err := models.ParseFilter(expr)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+
+	return filters, nil
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pt-brm/internal/models"
+	"pt-brm/internal/services"
+	"pt-brm/pkg/response"
+)
+
+func TestParseFilters(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    []string
+		wantErr bool
+	}{
+		{query: "", want: nil},
+		{query: "limit=10&offset=5&sort=-age&include_deleted=true&format=csv&cursor=abc", want: nil},
+		{query: "age>=18&limit=10", want: []string{"age>=18"}},
+		{query: "age%3E%3D18&name~=ana", want: []string{"age>=18", "name~=ana"}},
+		{query: "email@=example.com&&age<65", want: []string{"email@=example.com", "age<65"}},
+		{query: "name=ana%20mar%C3%ADa", want: []string{"name=ana maría"}},
+		{query: "name=%zz", wantErr: true},
+		{query: "password=x", wantErr: true},
+		{query: "age>=veinte", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			filters, err := parseFilters(tt.query)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseFilters(%q) = %+v, se esperaba un error", tt.query, filters)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseFilters(%q): %v", tt.query, err)
+			}
+			if len(filters) != len(tt.want) {
+				t.Fatalf("parseFilters(%q) = %d filtros, se esperaban %d", tt.query, len(filters), len(tt.want))
+			}
+			for i, expr := range tt.want {
+				want, _ := models.ParseFilter(expr)
+				got := filters[i]
+				if got.Field != want.Field || got.Operator != want.Operator || got.Value != want.Value {
+					t.Errorf("filtro %d = %+v, se esperaba %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseListParams(t *testing.T) {
+	cursor := models.Cursor{Sort: "age,id", Values: []string{"30", "7"}}.Encode()
+
+	tests := []struct {
+		query    string
+		wantCode string
+	}{
+		{query: ""},
+		{query: "limit=50&offset=10&sort=-age,name&age>=18"},
+		{query: "sort=age&cursor=" + cursor},
+		{query: "include_deleted=true"},
+		{query: "limit=muchos", wantCode: "invalid_limit"},
+		{query: "offset=-1", wantCode: "invalid_offset"},
+		{query: "limit=101", wantCode: "invalid_limit"},
+		{query: "include_deleted=quizas", wantCode: "invalid_include_deleted"},
+		{query: "sort=password", wantCode: "invalid_sort"},
+		{query: "age~=1", wantCode: "invalid_filter"},
+		{query: "cursor=%%%", wantCode: "invalid_filter"},
+		{query: "cursor=" + cursor, wantCode: "invalid_cursor"},
+		{query: "sort=age&offset=5&cursor=" + cursor, wantCode: "cursor_with_offset"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/v1/users?"+tt.query, nil)
+			params, err := parseListParams(r)
+			if tt.wantCode != "" {
+				var domainErr *models.DomainError
+				if !errors.As(err, &domainErr) || domainErr.Code != tt.wantCode {
+					t.Fatalf("parseListParams(%q) = %+v, %v; se esperaba el error %s", tt.query, params, err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseListParams(%q): %v", tt.query, err)
+			}
+		})
+	}
+}
+
+func TestGetAllUsersValidationError(t *testing.T) {
+	handler := NewUserHandler(nil, 0)
+
+	r := httptest.NewRequest("GET", "/api/v1/users?sort=password", nil)
+	w := httptest.NewRecorder()
+	handler.GetAllUsers(w, r)
+
+	var body response.APIResponse
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("respuesta inválida: %v", err)
+	}
+	if w.Code != http.StatusUnprocessableEntity || body.Code != "invalid_sort" || body.Field != "sort" {
+		t.Errorf("respuesta = %d %+v, se esperaba 422 con invalid_sort en sort", w.Code, body)
+	}
+}
+
+// fakeUsers registra la retención con la que se pidió la purga.
+type fakeUsers struct {
+	services.UserService
+	purged *time.Duration
+}
+
+func (f fakeUsers) PurgeDeletedUsers(ctx context.Context, retention time.Duration) (int64, error) {
+	*f.purged = retention
+	return 0, nil
+}
+
+func TestPurgeUsersMinimumAge(t *testing.T) {
+	tests := []struct {
+		olderThan string
+		want      int
+	}{
+		{olderThan: "720h", want: http.StatusOK},
+		{olderThan: "1000h", want: http.StatusOK},
+		{olderThan: "719h59m", want: http.StatusBadRequest},
+		{olderThan: "0s", want: http.StatusBadRequest},
+		{olderThan: "-1h", want: http.StatusBadRequest},
+		{olderThan: "", want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.olderThan, func(t *testing.T) {
+			purged := time.Duration(-1)
+			handler := NewUserHandler(fakeUsers{purged: &purged}, 720*time.Hour)
+
+			r := httptest.NewRequest("POST", "/api/v1/users/purge?older_than="+tt.olderThan, nil)
+			w := httptest.NewRecorder()
+			handler.PurgeUsers(w, r)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, se esperaba %d (%s)", w.Code, tt.want, w.Body.String())
+			}
+			if tt.want != http.StatusOK && purged != -1 {
+				t.Errorf("se purgó con older_than=%s", tt.olderThan)
+			}
+		})
+	}
+}
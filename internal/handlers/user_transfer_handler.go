@@ -0,0 +1,404 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"pt-brm/internal/models"
+	"pt-brm/pkg/response"
+	"pt-brm/pkg/xlsx"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tamaño máximo de un archivo de importación y de cada línea NDJSON.
+const (
+	maxImportBytes     = 100 << 20
+	maxNDJSONLineBytes = 1 << 20
+)
+
+// Columnas de la exportación; la importación solo usa name, email y age e
+// ignora el resto, de modo que un archivo exportado se puede volver a importar.
+var exportColumns = []string{"id", "name", "email", "age", "version", "created_at", "updated_at", "deleted_at"}
+
+// Caracteres con los que Excel y LibreOffice interpretan una celda como fórmula.
+const formulaPrefixes = "=+-@\t\r"
+
+// GET /users/export?format=csv|ndjson|xlsx - Exportar usuarios.
+// Acepta los mismos filtros, orden e include_deleted que el listado, pero no
+// su paginación: siempre exporta todos los usuarios que cumplen los filtros.
+func (h *UserHandler) ExportUsers(w http.ResponseWriter, r *http.Request) {
+	for _, name := range []string{"limit", "offset", "cursor"} {
+		if r.URL.Query().Has(name) {
+			response.FromError(w, models.NewValidationError("pagination_not_supported", name, fmt.Sprintf("la exportación no admite %s: incluye todos los usuarios que cumplen los filtros", name)))
+			return
+		}
+	}
+
+	params, err := parseListParams(r)
+	if err != nil {
+		response.FromError(w, err)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+
+	exporter, err := newUserExporter(format, w)
+	if err != nil {
+		response.FromError(w, err)
+		return
+	}
+
+	err = h.userService.ExportUsers(r.Context(), params, exporter.Write)
+	if err != nil {
+		// Una vez enviados los headers ya no se puede responder con un error JSON
+		if !exporter.Started() {
+			response.FromError(w, err)
+			return
+		}
+		log.Printf("Error durante la exportación de usuarios: %v", err)
+		return
+	}
+
+	if err := exporter.Close(); err != nil {
+		log.Printf("Error al finalizar la exportación de usuarios: %v", err)
+	}
+}
+
+// POST /users/import?format=csv|ndjson&dry_run=true - Importar usuarios.
+// El archivo puede enviarse como cuerpo de la solicitud o como el campo
+// "file" de un formulario multipart.
+func (h *UserHandler) ImportUsers(w http.ResponseWriter, r *http.Request) {
+	dryRun := false
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "dry_run inválido")
+			return
+		}
+		dryRun = parsed
+	}
+
+	body, format, err := importSource(w, r)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var next func() (*models.ImportRecord, error)
+	switch format {
+	case "csv":
+		next, err = csvRecords(body)
+	case "ndjson":
+		next = ndjsonRecords(body)
+	default:
+		err = fmt.Errorf("formato de importación no soportado: %q", format)
+	}
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	report, err := h.userService.ImportUsers(r.Context(), next, dryRun)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			response.Error(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("el archivo supera el máximo de %d bytes", maxBytesErr.Limit))
+			return
+		}
+		response.FromError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, report)
+}
+
+// importSource devuelve el contenido a importar y su formato, tomado de
+// ?format=, del Content-Type o de la extensión del archivo subido.
+func importSource(w http.ResponseWriter, r *http.Request) (io.Reader, string, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+	format := r.URL.Query().Get("format")
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		if format == "" {
+			format = formatFromMediaType(mediaType)
+		}
+		return r.Body, format, nil
+	}
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, "", errors.New("formulario multipart inválido")
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return nil, "", errors.New(`el formulario no contiene el campo "file"`)
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+
+		if format == "" {
+			format = strings.TrimPrefix(path.Ext(part.FileName()), ".")
+		}
+		if format == "" {
+			partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+			format = formatFromMediaType(partType)
+		}
+		return part, format, nil
+	}
+}
+
+func formatFromMediaType(mediaType string) string {
+	switch mediaType {
+	case "text/csv":
+		return "csv"
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return "ndjson"
+	}
+	return ""
+}
+
+// csvRecords lee un CSV con encabezado. Las columnas name, email y age son
+// obligatorias y pueden estar en cualquier orden.
+func csvRecords(body io.Reader) (func() (*models.ImportRecord, error), error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("no se pudo leer el encabezado del CSV")
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"name", "email", "age"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("el CSV no tiene la columna %q", required)
+		}
+	}
+
+	return func() (*models.ImportRecord, error) {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return &models.ImportRecord{Line: parseErr.Line, Err: errors.New(parseErr.Err.Error())}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		record := &models.ImportRecord{Line: line}
+
+		field := func(name string) string {
+			if i := columns[name]; i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		record.Request.Name = unescapeFormula(field("name"))
+		record.Request.Email = unescapeFormula(field("email"))
+		age, err := strconv.Atoi(field("age"))
+		if err != nil {
+			record.Err = models.NewValidationError("invalid_age", "age", "la edad debe ser un número entero")
+		}
+		record.Request.Age = age
+
+		return record, nil
+	}, nil
+}
+
+// ndjsonRecords lee un objeto JSON por línea, ignorando las líneas vacías.
+func ndjsonRecords(body io.Reader) func() (*models.ImportRecord, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), maxNDJSONLineBytes)
+	line := 0
+
+	return func() (*models.ImportRecord, error) {
+		for scanner.Scan() {
+			line++
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" {
+				continue
+			}
+
+			record := &models.ImportRecord{Line: line}
+			if err := json.Unmarshal([]byte(text), &record.Request); err != nil {
+				record.Err = errors.New("JSON inválido: " + err.Error())
+			}
+			return record, nil
+		}
+
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+}
+
+// userExporter escribe los usuarios en un formato de exportación. Los headers
+// HTTP se envían recién con la primera fila (o al cerrar), para que un error
+// en la consulta todavía pueda responderse como JSON.
+type userExporter struct {
+	w           http.ResponseWriter
+	contentType string
+	extension   string
+	started     bool
+
+	begin func() error
+	write func(user *models.User) error
+	close func() error
+}
+
+func newUserExporter(format string, w http.ResponseWriter) (*userExporter, error) {
+	e := &userExporter{w: w, extension: format}
+
+	switch format {
+	case "csv":
+		writer := csv.NewWriter(w)
+		e.contentType = "text/csv; charset=utf-8"
+		e.begin = func() error { return writer.Write(exportColumns) }
+		e.write = func(user *models.User) error {
+			return writer.Write(userRow(user, csvValue))
+		}
+		e.close = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	case "ndjson":
+		encoder := json.NewEncoder(w)
+		e.contentType = "application/x-ndjson"
+		e.begin = func() error { return nil }
+		e.write = func(user *models.User) error { return encoder.Encode(user) }
+		e.close = func() error { return nil }
+	case "xlsx":
+		var writer *xlsx.Writer
+		e.contentType = xlsx.ContentType
+		e.begin = func() error {
+			var err error
+			if writer, err = xlsx.NewWriter(w, "users"); err != nil {
+				return err
+			}
+			header := make([]any, len(exportColumns))
+			for i, column := range exportColumns {
+				header[i] = column
+			}
+			return writer.WriteRow(header...)
+		}
+		e.write = func(user *models.User) error {
+			return writer.WriteRow(userRow(user, func(v any) any { return v })...)
+		}
+		e.close = func() error { return writer.Close() }
+	default:
+		return nil, models.NewValidationError("invalid_format", "format", fmt.Sprintf("formato de exportación no soportado: %q", format))
+	}
+
+	return e, nil
+}
+
+func (e *userExporter) Started() bool {
+	return e.started
+}
+
+func (e *userExporter) Write(user *models.User) error {
+	if err := e.start(); err != nil {
+		return err
+	}
+	return e.write(user)
+}
+
+func (e *userExporter) Close() error {
+	if err := e.start(); err != nil {
+		return err
+	}
+	return e.close()
+}
+
+func (e *userExporter) start() error {
+	if e.started {
+		return nil
+	}
+	e.started = true
+
+	e.w.Header().Set("Content-Type", e.contentType)
+	e.w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="users.%s"`, e.extension))
+	e.w.WriteHeader(http.StatusOK)
+
+	return e.begin()
+}
+
+// userRow devuelve los valores de un usuario en el orden de exportColumns.
+func userRow[T any](user *models.User, convert func(v any) T) []T {
+	var deletedAt any = ""
+	if user.DeletedAt != nil {
+		deletedAt = user.DeletedAt.Format(time.RFC3339)
+	}
+
+	values := []any{
+		user.ID,
+		user.Name,
+		user.Email,
+		user.Age,
+		user.Version,
+		user.CreatedAt.Format(time.RFC3339),
+		user.UpdatedAt.Format(time.RFC3339),
+		deletedAt,
+	}
+
+	row := make([]T, len(values))
+	for i, v := range values {
+		row[i] = convert(v)
+	}
+	return row
+}
+
+// csvValue convierte un valor de userRow en una celda CSV. Solo el CSV se
+// escapa: las celdas de texto del XLSX nunca se evalúan como fórmulas.
+func csvValue(v any) string {
+	if s, ok := v.(string); ok {
+		return escapeFormula(s)
+	}
+	return fmt.Sprint(v)
+}
+
+// escapeFormula antepone un apóstrofo a los textos que una planilla
+// interpretaría como fórmula, para evitar la inyección de fórmulas al abrir
+// la exportación.
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// unescapeFormula revierte escapeFormula, para que un archivo exportado se
+// importe con los valores originales.
+func unescapeFormula(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
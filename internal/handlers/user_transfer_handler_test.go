@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pt-brm/internal/models"
+	"pt-brm/pkg/response"
+)
+
+func TestEscapeFormula(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "", want: ""},
+		{value: "Ana", want: "Ana"},
+		{value: "=HYPERLINK(\"http://x\")", want: "'=HYPERLINK(\"http://x\")"},
+		{value: "+1", want: "'+1"},
+		{value: "-1", want: "'-1"},
+		{value: "@SUM(A1)", want: "'@SUM(A1)"},
+		{value: "\tcmd", want: "'\tcmd"},
+		{value: "\rcmd", want: "'\rcmd"},
+		{value: "a=b", want: "a=b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := escapeFormula(tt.value); got != tt.want {
+				t.Errorf("escapeFormula(%q) = %q, se esperaba %q", tt.value, got, tt.want)
+			}
+			if got := unescapeFormula(escapeFormula(tt.value)); got != tt.value {
+				t.Errorf("unescapeFormula(escapeFormula(%q)) = %q", tt.value, got)
+			}
+		})
+	}
+}
+
+func TestCSVExportEscapesFormulas(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	exporter, err := newUserExporter("csv", recorder)
+	if err != nil {
+		t.Fatalf("newUserExporter: %v", err)
+	}
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	user := &models.User{ID: 1, Name: "=1+1", Email: "@ana@example.com", Age: 30, Version: 1, CreatedAt: now, UpdatedAt: now}
+	if err := exporter.Write(user); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := exporter.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	next, err := csvRecords(strings.NewReader(recorder.Body.String()))
+	if err != nil {
+		t.Fatalf("csvRecords: %v", err)
+	}
+	record, err := next()
+	if err != nil {
+		t.Fatalf("next: %v", err)
+	}
+
+	if !strings.Contains(recorder.Body.String(), "'=1+1,'@ana@example.com") {
+		t.Errorf("la exportación no escapa las fórmulas:\n%s", recorder.Body.String())
+	}
+	if record.Request.Name != user.Name || record.Request.Email != user.Email {
+		t.Errorf("importación = %q, %q; se esperaba %q, %q", record.Request.Name, record.Request.Email, user.Name, user.Email)
+	}
+}
+
+func TestXLSXExportKeepsRawValues(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	exporter, err := newUserExporter("xlsx", recorder)
+	if err != nil {
+		t.Fatalf("newUserExporter: %v", err)
+	}
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	user := &models.User{ID: 1, Name: "=1+1", Email: "@ana@example.com", Age: 30, Version: 1, CreatedAt: now, UpdatedAt: now}
+	if err := exporter.Write(user); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := exporter.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	body := recorder.Body.Bytes()
+	archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatalf("zip.NewReader: %v", err)
+	}
+	sheet, err := archive.Open("xl/worksheets/sheet1.xml")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer sheet.Close()
+	content, err := io.ReadAll(sheet)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+
+	// Las celdas inlineStr no se evalúan, así que no se escapan
+	for _, value := range []string{user.Name, user.Email} {
+		if !bytes.Contains(content, []byte(">"+value+"<")) {
+			t.Errorf("la hoja no contiene %q sin modificar:\n%s", value, content)
+		}
+	}
+	if bytes.Contains(content, []byte("'")) {
+		t.Errorf("la hoja contiene valores escapados:\n%s", content)
+	}
+}
+
+func TestExportUsersRejectsPagination(t *testing.T) {
+	handler := NewUserHandler(nil, 0)
+
+	for _, query := range []string{"limit=10", "offset=5", "cursor=abc", "format=csv&limit=0"} {
+		t.Run(query, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/v1/users/export?"+query, nil)
+			w := httptest.NewRecorder()
+			handler.ExportUsers(w, r)
+
+			var body response.APIResponse
+			if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+				t.Fatalf("respuesta inválida: %v", err)
+			}
+			if w.Code != http.StatusUnprocessableEntity || body.Code != "pagination_not_supported" {
+				t.Errorf("respuesta = %d %+v, se esperaba 422 con pagination_not_supported", w.Code, body)
+			}
+		})
+	}
+}
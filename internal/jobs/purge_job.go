@@ -0,0 +1,58 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"pt-brm/internal/config"
+	"pt-brm/internal/services"
+	"time"
+)
+
+// PurgeJob elimina periódicamente los usuarios que llevan eliminados (soft
+// delete) más tiempo que la retención configurada.
+type PurgeJob struct {
+	userService services.UserService
+	retention   time.Duration
+	interval    time.Duration
+}
+
+func NewPurgeJob(userService services.UserService, cfg config.PurgeConfig) *PurgeJob {
+	return &PurgeJob{
+		userService: userService,
+		retention:   cfg.Retention,
+		interval:    cfg.Interval,
+	}
+}
+
+// Run ejecuta la purga al iniciar y luego en cada intervalo, hasta que ctx se cancele.
+func (j *PurgeJob) Run(ctx context.Context) {
+	if j.retention <= 0 || j.interval <= 0 {
+		log.Println("Job de purga de usuarios desactivado")
+		return
+	}
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j *PurgeJob) purge(ctx context.Context) {
+	purged, err := j.userService.PurgeDeletedUsers(ctx, j.retention)
+	if err != nil {
+		log.Printf("Error al purgar usuarios eliminados: %v", err)
+		return
+	}
+
+	if purged > 0 {
+		log.Printf("Se purgaron %d usuarios eliminados hace más de %s", purged, j.retention)
+	}
+}
@@ -0,0 +1,43 @@
+// Package logging configura los logs con nivel (log/slog). El nivel se puede
+// cambiar en caliente con LOG_LEVEL al recargar la configuración.
+package logging
+
+import (
+	"log"
+	"log/slog"
+	"os"
+	"pt-brm/internal/config"
+)
+
+// level es el nivel mínimo de los logs de slog.
+var level = new(slog.LevelVar)
+
+// Setup configura slog como logger por defecto con el nivel indicado. Los
+// mensajes del paquete log se siguen escribiendo siempre, sin importar el nivel.
+func Setup(name string) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
+
+	// slog.SetDefault redirige el paquete log a slog; se restaura para que
+	// log.Fatal y los mensajes existentes no dependan del nivel
+	log.SetOutput(os.Stderr)
+	log.SetFlags(log.LstdFlags)
+
+	SetLevel(name)
+}
+
+// SetLevel cambia el nivel mínimo (debug, info, warn o error).
+func SetLevel(name string) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(name)); err != nil {
+		log.Printf("Warning: nivel de log inválido %q, usando info", name)
+		l = slog.LevelInfo
+	}
+	level.Set(l)
+}
+
+// Observer aplica LOG_LEVEL tras cada recarga de la configuración.
+type Observer struct{}
+
+func (Observer) ConfigChanged(cfg *config.Config) {
+	SetLevel(cfg.LogLevel)
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// Permisos (scopes) que puede tener una API key o un token.
+const (
+	ScopeUsersRead  = "users:read"
+	ScopeUsersWrite = "users:write"
+	// ScopeAPIKeysManage permite administrar las API keys; solo se concede
+	// por token, una API key no puede tenerlo.
+	ScopeAPIKeysManage = "api_keys:manage"
+	// ScopeUsersAdmin permite asignar la contraseña de otro usuario sin la
+	// actual; tampoco se puede asignar a una API key.
+	ScopeUsersAdmin = "users:admin"
+)
+
+// APIKeyScopes son los permisos que se pueden asignar a una API key.
+var APIKeyScopes = []string{ScopeUsersRead, ScopeUsersWrite}
+
+// APIKey es una credencial para clientes de servicio a servicio. Solo se
+// guarda un hash con sal del secreto; el prefijo identifica la clave sin
+// revelarlo.
+type APIKey struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	Salt       string     `json:"-"`
+	Hash       string     `json:"-"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// CreateAPIKeyRequest son los datos para crear una API key.
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// IssuedAPIKey es la respuesta al crear o rotar una API key; Key es el
+// secreto completo y no se vuelve a mostrar.
+type IssuedAPIKey struct {
+	*APIKey
+	Key string `json:"key"`
+}
+
+// Active indica si la clave puede usarse en el instante indicado.
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// Validate comprueba los datos de una API key nueva.
+func (r *CreateAPIKeyRequest) Validate(now time.Time) error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return NewValidationError("name_required", "name", "el nombre es requerido")
+	}
+	if len(r.Name) > 80 {
+		return NewValidationError("value_too_long", "name", "el valor de name es demasiado largo")
+	}
+	if len(r.Scopes) == 0 {
+		return NewValidationError("scopes_required", "scopes", "se requiere al menos un scope")
+	}
+	for _, scope := range r.Scopes {
+		if !slices.Contains(APIKeyScopes, scope) {
+			return NewValidationError("invalid_scope", "scopes", "scope no válido: "+scope+" (se admiten "+strings.Join(APIKeyScopes, ", ")+")")
+		}
+	}
+	if r.ExpiresAt != nil && !r.ExpiresAt.After(now) {
+		return NewValidationError("invalid_expiry", "expires_at", "la fecha de expiración debe ser futura")
+	}
+	return nil
+}
+
+// Errores concretos del dominio de API keys.
+var (
+	ErrAPIKeyNotFound = NewNotFoundError("api_key_not_found", "API key no encontrada")
+	ErrAPIKeyRevoked  = NewConflictError("api_key_revoked", "la API key está revocada")
+)
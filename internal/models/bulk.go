@@ -0,0 +1,126 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MaxBulkItems es la cantidad máxima de elementos aceptados en una operación masiva.
+const MaxBulkItems = 10000
+
+// BulkMode indica cómo se comporta una operación masiva ante errores.
+type BulkMode string
+
+const (
+	// BulkAtomic aplica todos los elementos o ninguno.
+	BulkAtomic BulkMode = "atomic"
+	// BulkBestEffort aplica los elementos válidos y reporta los que fallan.
+	BulkBestEffort BulkMode = "best_effort"
+)
+
+// Estados posibles de cada elemento de una operación masiva.
+const (
+	BulkStatusCreated    = "created"
+	BulkStatusUpdated    = "updated"
+	BulkStatusDeleted    = "deleted"
+	BulkStatusFailed     = "failed"
+	BulkStatusNotApplied = "not_applied"
+)
+
+// BulkPatchItem es un elemento de una actualización parcial masiva.
+type BulkPatchItem struct {
+	ID      int              `json:"id"`
+	Version int              `json:"version,omitempty"`
+	Changes PatchUserRequest `json:"changes"`
+}
+
+// BulkItemResult es el resultado de un elemento, identificado por su posición en la solicitud.
+type BulkItemResult struct {
+	Index  int    `json:"index"`
+	Status string `json:"status"`
+	ID     int    `json:"id,omitempty"`
+	User   *User  `json:"user,omitempty"`
+	Code   string `json:"code,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BulkResult es el resultado de una operación masiva.
+type BulkResult struct {
+	Mode      BulkMode         `json:"mode"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Items     []BulkItemResult `json:"items"`
+}
+
+// ParseBulkMode interpreta el modo recibido; por defecto la operación es atómica.
+func ParseBulkMode(value string) (BulkMode, error) {
+	switch BulkMode(value) {
+	case "", BulkAtomic:
+		return BulkAtomic, nil
+	case BulkBestEffort:
+		return BulkBestEffort, nil
+	default:
+		return "", NewValidationError("invalid_bulk_mode", "mode", "el modo debe ser atomic o best_effort")
+	}
+}
+
+// ValidateBulkSize verifica que la cantidad de elementos esté dentro del límite.
+func ValidateBulkSize(count int) error {
+	if count == 0 {
+		return NewValidationError("empty_bulk", "items", "la solicitud no contiene elementos")
+	}
+	if count > MaxBulkItems {
+		return NewValidationError("bulk_too_large", "items", fmt.Sprintf("se admiten como máximo %d elementos", MaxBulkItems))
+	}
+	return nil
+}
+
+// NewBulkResult arma el resultado a partir del estado de éxito y del error de
+// cada elemento. En modo atómico, si algún elemento falló, los demás quedan
+// como no aplicados.
+func NewBulkResult(mode BulkMode, success string, users []*User, ids []int, errs []error) *BulkResult {
+	result := &BulkResult{Mode: mode, Items: make([]BulkItemResult, len(errs))}
+
+	failed := false
+	for _, err := range errs {
+		if err != nil {
+			failed = true
+			break
+		}
+	}
+
+	for i, err := range errs {
+		item := BulkItemResult{Index: i}
+		if i < len(ids) {
+			item.ID = ids[i]
+		}
+
+		switch {
+		case err != nil:
+			item.Status = BulkStatusFailed
+			item.Code, item.Error = bulkErrorDetails(err)
+			result.Failed++
+		case failed && mode == BulkAtomic:
+			item.Status = BulkStatusNotApplied
+		default:
+			item.Status = success
+			if i < len(users) && users[i] != nil {
+				item.User = users[i]
+				item.ID = users[i].ID
+			}
+			result.Succeeded++
+		}
+
+		result.Items[i] = item
+	}
+
+	return result
+}
+
+func bulkErrorDetails(err error) (string, string) {
+	var domainErr *DomainError
+	if errors.As(err, &domainErr) {
+		return domainErr.Code, domainErr.Message
+	}
+	return "internal_error", err.Error()
+}
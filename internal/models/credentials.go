@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// LoginRequest son las credenciales de POST /auth/login.
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// LoginResponse es el token que se entrega al iniciar sesión.
+type LoginResponse struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresIn   int       `json:"expires_in"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	User        *User     `json:"user"`
+}
+
+// ChangePasswordRequest son los datos de POST /users/{id}/password.
+// CurrentPassword es obligatoria salvo cuando un administrador asigna la
+// contraseña de otro usuario.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// ErrInvalidCurrentPassword es retornado cuando la contraseña actual no coincide.
+var ErrInvalidCurrentPassword = NewValidationError("invalid_current_password", "current_password", "la contraseña actual no es correcta")
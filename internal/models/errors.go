@@ -0,0 +1,75 @@
+package models
+
+import "errors"
+
+// Categorías de error del dominio. Se comparan con errors.Is y cada capa
+// superior decide cómo representarlas (por ejemplo, el código HTTP).
+var (
+	ErrNotFound     = errors.New("no encontrado")
+	ErrConflict     = errors.New("conflicto")
+	ErrValidation   = errors.New("datos inválidos")
+	ErrPrecondition = errors.New("precondición fallida")
+	ErrInternal     = errors.New("error interno")
+)
+
+// DomainError es un error del dominio con una categoría (Kind), un código
+// estable para los clientes y opcionalmente el campo que lo provocó.
+type DomainError struct {
+	Kind    error
+	Code    string
+	Message string
+	Field   string
+	Err     error
+}
+
+func (e *DomainError) Error() string {
+	return e.Message
+}
+
+// Unwrap permite que errors.Is/As encuentren tanto la categoría como la causa.
+func (e *DomainError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// NewNotFoundError crea un error de recurso inexistente.
+func NewNotFoundError(code, message string) *DomainError {
+	return &DomainError{Kind: ErrNotFound, Code: code, Message: message}
+}
+
+// NewConflictError crea un error de conflicto con el estado actual del recurso.
+func NewConflictError(code, message string) *DomainError {
+	return &DomainError{Kind: ErrConflict, Code: code, Message: message}
+}
+
+// NewValidationError crea un error de validación sobre un campo.
+func NewValidationError(code, field, message string) *DomainError {
+	return &DomainError{Kind: ErrValidation, Code: code, Field: field, Message: message}
+}
+
+// NewInternalError envuelve un error inesperado (base de datos, E/S...).
+func NewInternalError(message string, err error) *DomainError {
+	return &DomainError{Kind: ErrInternal, Code: "internal_error", Message: message, Err: err}
+}
+
+// Errores concretos del dominio de usuarios.
+var (
+	ErrUserNotFound       = NewNotFoundError("user_not_found", "usuario no encontrado")
+	ErrUserNotDeleted     = NewConflictError("user_not_deleted", "el usuario no está eliminado")
+	ErrEmailAlreadyExists = &DomainError{
+		Kind:    ErrConflict,
+		Code:    "email_already_exists",
+		Field:   "email",
+		Message: "el email ya existe",
+	}
+
+	// ErrPreconditionFailed es retornado cuando la versión esperada del usuario
+	// (If-Match) no coincide con la almacenada.
+	ErrPreconditionFailed = &DomainError{
+		Kind:    ErrPrecondition,
+		Code:    "precondition_failed",
+		Message: "el usuario fue modificado por otra solicitud",
+	}
+)
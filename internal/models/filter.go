@@ -0,0 +1,267 @@
+package models
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FilterOperator es un operador admitido en los filtros del listado de usuarios.
+type FilterOperator string
+
+const (
+	OpEq       FilterOperator = "="
+	OpNe       FilterOperator = "!="
+	OpGt       FilterOperator = ">"
+	OpGte      FilterOperator = ">="
+	OpLt       FilterOperator = "<"
+	OpLte      FilterOperator = "<="
+	OpContains FilterOperator = "~="
+	OpDomain   FilterOperator = "@="
+)
+
+// Orden importante: los operadores de dos caracteres se evalúan primero.
+var filterOperators = []FilterOperator{OpGte, OpLte, OpNe, OpContains, OpDomain, OpGt, OpLt, OpEq}
+
+// FieldKind indica cómo se interpreta el valor de un campo filtrable.
+type FieldKind int
+
+const (
+	FieldInt FieldKind = iota
+	FieldString
+	FieldTime
+)
+
+// FilterField describe un campo de User que se puede filtrar y ordenar.
+type FilterField struct {
+	Column    string
+	Kind      FieldKind
+	Operators []FilterOperator
+}
+
+var (
+	numericOperators = []FilterOperator{OpEq, OpNe, OpGt, OpGte, OpLt, OpLte}
+	timeOperators    = []FilterOperator{OpEq, OpGt, OpGte, OpLt, OpLte}
+)
+
+// userFields es la lista blanca de campos de User expuestos al lenguaje de consulta.
+var userFields = map[string]FilterField{
+	"id":         {Column: "id", Kind: FieldInt, Operators: numericOperators},
+	"name":       {Column: "name", Kind: FieldString, Operators: []FilterOperator{OpEq, OpNe, OpContains}},
+	"email":      {Column: "email", Kind: FieldString, Operators: []FilterOperator{OpEq, OpNe, OpContains, OpDomain}},
+	"age":        {Column: "age", Kind: FieldInt, Operators: numericOperators},
+	"created_at": {Column: "created_at", Kind: FieldTime, Operators: timeOperators},
+	"updated_at": {Column: "updated_at", Kind: FieldTime, Operators: timeOperators},
+}
+
+// Filter es una condición ya validada sobre un campo de User.
+type Filter struct {
+	Field    string
+	Column   string
+	Operator FilterOperator
+	Value    any
+}
+
+// SortField es un criterio de ordenamiento del listado.
+type SortField struct {
+	Field  string
+	Column string
+	Desc   bool
+}
+
+// ParseFilter interpreta una expresión como "age>=18" o "name~=ana".
+func ParseFilter(expr string) (Filter, error) {
+	i := strings.IndexAny(expr, "=<>!~@")
+	if i <= 0 {
+		return Filter{}, NewValidationError("invalid_filter", "", fmt.Sprintf("filtro inválido: %q", expr))
+	}
+
+	name, rest := expr[:i], expr[i:]
+	field, ok := userFields[name]
+	if !ok {
+		return Filter{}, NewValidationError("invalid_filter", name, fmt.Sprintf("el campo %q no se puede filtrar", name))
+	}
+
+	var op FilterOperator
+	for _, candidate := range filterOperators {
+		if strings.HasPrefix(rest, string(candidate)) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" || !slices.Contains(field.Operators, op) {
+		return Filter{}, NewValidationError("invalid_filter", name, fmt.Sprintf("operador no soportado para el campo %q", name))
+	}
+
+	raw := rest[len(op):]
+	if raw == "" {
+		return Filter{}, NewValidationError("invalid_filter", name, fmt.Sprintf("el filtro sobre %q requiere un valor", name))
+	}
+
+	value, err := field.parseValue(raw)
+	if err != nil {
+		return Filter{}, NewValidationError("invalid_filter", name, fmt.Sprintf("valor inválido para el campo %q: %v", name, err))
+	}
+
+	return Filter{Field: name, Column: field.Column, Operator: op, Value: value}, nil
+}
+
+// ParseSort interpreta una lista como "-age,name"; el prefijo "-" indica orden descendente.
+func ParseSort(raw string) ([]SortField, error) {
+	var fields []SortField
+	seen := map[string]bool{}
+
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		desc := strings.HasPrefix(part, "-")
+		name := strings.TrimPrefix(strings.TrimPrefix(part, "-"), "+")
+
+		field, ok := userFields[name]
+		if !ok {
+			return nil, NewValidationError("invalid_sort", "sort", fmt.Sprintf("no se puede ordenar por el campo %q", name))
+		}
+		if seen[name] {
+			return nil, NewValidationError("invalid_sort", "sort", fmt.Sprintf("el campo %q está repetido en sort", name))
+		}
+		seen[name] = true
+
+		fields = append(fields, SortField{Field: name, Column: field.Column, Desc: desc})
+	}
+
+	return fields, nil
+}
+
+// FormatSort es la operación inversa de ParseSort.
+func FormatSort(fields []SortField) string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		if f.Desc {
+			parts[i] = "-" + f.Field
+		} else {
+			parts[i] = f.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func (f FilterField) parseValue(raw string) (any, error) {
+	switch f.Kind {
+	case FieldInt:
+		return strconv.Atoi(raw)
+	case FieldTime:
+		return parseTimeValue(raw)
+	default:
+		return raw, nil
+	}
+}
+
+// parseTimeValue acepta fechas completas RFC 3339 o solo la fecha (2006-01-02).
+func parseTimeValue(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, raw); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation(time.DateOnly, raw, time.Local)
+}
+
+// fieldValue devuelve el valor de un campo ordenable como texto, para guardarlo en un cursor.
+func (u *User) fieldValue(name string) string {
+	switch name {
+	case "id":
+		return strconv.Itoa(u.ID)
+	case "name":
+		return u.Name
+	case "email":
+		return u.Email
+	case "age":
+		return strconv.Itoa(u.Age)
+	case "created_at":
+		return u.CreatedAt.Format(time.RFC3339Nano)
+	case "updated_at":
+		return u.UpdatedAt.Format(time.RFC3339Nano)
+	}
+	return ""
+}
+
+// Matches indica si el usuario cumple el filtro, para evaluarlo fuera de la
+// base de datos. Las cadenas se comparan sin distinguir mayúsculas, igual que
+// la intercalación de la tabla users.
+func (f Filter) Matches(u *User) bool {
+	switch f.Operator {
+	case OpContains:
+		return strings.Contains(strings.ToLower(u.field(f.Field).(string)), strings.ToLower(f.Value.(string)))
+	case OpDomain:
+		domain := "@" + strings.TrimPrefix(f.Value.(string), "@")
+		return strings.HasSuffix(strings.ToLower(u.field(f.Field).(string)), strings.ToLower(domain))
+	}
+
+	c := u.CompareField(f.Field, f.Value)
+	switch f.Operator {
+	case OpEq:
+		return c == 0
+	case OpNe:
+		return c != 0
+	case OpGt:
+		return c > 0
+	case OpGte:
+		return c >= 0
+	case OpLt:
+		return c < 0
+	case OpLte:
+		return c <= 0
+	}
+	return false
+}
+
+// CompareField compara el campo name del usuario con value, que debe ser del
+// tipo del campo (el mismo que producen ParseFilter y CursorValues).
+func (u *User) CompareField(name string, value any) int {
+	return compareValues(u.field(name), value)
+}
+
+// CompareUsers compara dos usuarios según order, respetando la dirección de cada campo.
+func CompareUsers(a, b *User, order []SortField) int {
+	for _, f := range order {
+		c := compareValues(a.field(f.Field), b.field(f.Field))
+		if f.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func compareValues(a, b any) int {
+	switch a := a.(type) {
+	case int:
+		return cmp.Compare(a, b.(int))
+	case string:
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b.(string)))
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	}
+	return 0
+}
+
+// field devuelve el valor tipado de un campo filtrable u ordenable.
+func (u *User) field(name string) any {
+	switch name {
+	case "id":
+		return u.ID
+	case "name":
+		return u.Name
+	case "email":
+		return u.Email
+	case "age":
+		return u.Age
+	case "created_at":
+		return u.CreatedAt
+	case "updated_at":
+		return u.UpdatedAt
+	}
+	return nil
+}
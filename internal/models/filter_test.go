@@ -0,0 +1,128 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    Filter
+		wantErr string
+	}{
+		{expr: "age>=18", want: Filter{Field: "age", Column: "age", Operator: OpGte, Value: 18}},
+		{expr: "age<=65", want: Filter{Field: "age", Column: "age", Operator: OpLte, Value: 65}},
+		{expr: "age>18", want: Filter{Field: "age", Column: "age", Operator: OpGt, Value: 18}},
+		{expr: "id!=3", want: Filter{Field: "id", Column: "id", Operator: OpNe, Value: 3}},
+		{expr: "name~=ana", want: Filter{Field: "name", Column: "name", Operator: OpContains, Value: "ana"}},
+		{expr: "email@=example.com", want: Filter{Field: "email", Column: "email", Operator: OpDomain, Value: "example.com"}},
+		{expr: "name=a=b", want: Filter{Field: "name", Column: "name", Operator: OpEq, Value: "a=b"}},
+		{
+			expr: "created_at>=2024-01-02T03:04:05Z",
+			want: Filter{Field: "created_at", Column: "created_at", Operator: OpGte, Value: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		},
+		{
+			expr: "updated_at<2024-01-02",
+			want: Filter{Field: "updated_at", Column: "updated_at", Operator: OpLt, Value: time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local)},
+		},
+		{expr: "age", wantErr: "filtro inválido"},
+		{expr: ">=18", wantErr: "filtro inválido"},
+		{expr: "password=x", wantErr: "no se puede filtrar"},
+		{expr: "age~=1", wantErr: "operador no soportado"},
+		{expr: "name@=example.com", wantErr: "operador no soportado"},
+		{expr: "created_at!=2024-01-01", wantErr: "operador no soportado"},
+		{expr: "age>=", wantErr: "requiere un valor"},
+		{expr: "age=treinta", wantErr: "valor inválido"},
+		{expr: "created_at>ayer", wantErr: "valor inválido"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := ParseFilter(tt.expr)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseFilter(%q) = %v, se esperaba un error con %q", tt.expr, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseFilter(%q): %v", tt.expr, err)
+			}
+			if got.Field != tt.want.Field || got.Column != tt.want.Column || got.Operator != tt.want.Operator {
+				t.Errorf("ParseFilter(%q) = %+v, se esperaba %+v", tt.expr, got, tt.want)
+			}
+			if compareValues(got.Value, tt.want.Value) != 0 {
+				t.Errorf("valor = %v, se esperaba %v", got.Value, tt.want.Value)
+			}
+		})
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    []SortField
+		wantErr string
+	}{
+		{raw: "name", want: []SortField{{Field: "name", Column: "name"}}},
+		{raw: "+name", want: []SortField{{Field: "name", Column: "name"}}},
+		{raw: "-age, name", want: []SortField{{Field: "age", Column: "age", Desc: true}, {Field: "name", Column: "name"}}},
+		{raw: "-created_at,id", want: []SortField{{Field: "created_at", Column: "created_at", Desc: true}, {Field: "id", Column: "id"}}},
+		{raw: "password", wantErr: "no se puede ordenar"},
+		{raw: "name,", wantErr: "no se puede ordenar"},
+		{raw: "age,-age", wantErr: "repetido"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := ParseSort(tt.raw)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseSort(%q) = %v, se esperaba un error con %q", tt.raw, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSort(%q): %v", tt.raw, err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseSort(%q) = %+v, se esperaba %+v", tt.raw, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("ParseSort(%q)[%d] = %+v, se esperaba %+v", tt.raw, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFilterMatches(t *testing.T) {
+	user := &User{ID: 7, Name: "Ana María", Email: "Ana@Example.com", Age: 30}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"age>=30", true},
+		{"age>30", false},
+		{"id!=7", false},
+		{"name=ana maría", true},
+		{"name~=MAR", true},
+		{"email@=example.COM", true},
+		{"email@=@example.com", true},
+		{"email@=ample.com", false},
+	}
+
+	for _, tt := range tests {
+		filter, err := ParseFilter(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseFilter(%q): %v", tt.expr, err)
+		}
+		if got := filter.Matches(user); got != tt.want {
+			t.Errorf("%q.Matches = %v, se esperaba %v", tt.expr, got, tt.want)
+		}
+	}
+}
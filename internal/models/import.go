@@ -0,0 +1,41 @@
+package models
+
+import "errors"
+
+// ImportRecord es una fila leída de un archivo de importación. Err indica que
+// la fila no se pudo interpretar (por ejemplo, JSON o CSV mal formado).
+type ImportRecord struct {
+	Line    int
+	Request CreateUserRequest
+	Err     error
+}
+
+// ImportLineError describe por qué una fila del archivo no se importó.
+type ImportLineError struct {
+	Line  int    `json:"line"`
+	Code  string `json:"code"`
+	Field string `json:"field,omitempty"`
+	Error string `json:"error"`
+}
+
+// ImportReport resume el resultado de una importación.
+type ImportReport struct {
+	DryRun   bool              `json:"dry_run"`
+	Total    int               `json:"total"`
+	Imported int               `json:"imported"`
+	Failed   int               `json:"failed"`
+	Errors   []ImportLineError `json:"errors"`
+}
+
+// AddError registra el error de una línea en el reporte.
+func (r *ImportReport) AddError(line int, err error) {
+	lineErr := ImportLineError{Line: line, Code: "invalid_row", Error: err.Error()}
+	var domainErr *DomainError
+	if errors.As(err, &domainErr) {
+		lineErr.Code = domainErr.Code
+		lineErr.Field = domainErr.Field
+	}
+
+	r.Errors = append(r.Errors, lineErr)
+	r.Failed++
+}
@@ -0,0 +1,137 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// DefaultPageLimit es la cantidad de registros por página cuando no se indica limit.
+	DefaultPageLimit = 20
+	// MaxPageLimit es la cantidad máxima de registros que se pueden pedir por página.
+	MaxPageLimit = 100
+)
+
+// ErrInvalidCursor es retornado cuando el cursor recibido no se puede decodificar.
+var ErrInvalidCursor = NewValidationError("invalid_cursor", "cursor", "el cursor no es válido")
+
+// Cursor identifica la posición del último registro entregado en una página.
+// Guarda el orden con el que fue generado y los valores de cada campo de ese
+// orden; el id siempre va al final para que el orden sea estable aunque varios
+// usuarios compartan los mismos valores (por ejemplo created_at).
+type Cursor struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+}
+
+// UserListParams agrupa los parámetros de paginación, filtrado y orden del
+// listado de usuarios. Offset y Cursor son excluyentes: si llega un cursor se
+// usa paginación keyset. IncludeDeleted incluye los usuarios eliminados.
+type UserListParams struct {
+	Limit          int
+	Offset         int
+	Cursor         *Cursor
+	Filters        []Filter
+	Sort           []SortField
+	IncludeDeleted bool
+}
+
+// UserPage es una página del listado de usuarios.
+type UserPage struct {
+	Users      []*User
+	Total      int
+	Limit      int
+	Offset     int
+	NextCursor string
+}
+
+// NewCursor construye el cursor que apunta al usuario indicado según el orden dado.
+func NewCursor(order []SortField, last *User) Cursor {
+	values := make([]string, len(order))
+	for i, f := range order {
+		values[i] = last.fieldValue(f.Field)
+	}
+	return Cursor{Sort: FormatSort(order), Values: values}
+}
+
+// Encode serializa el cursor como una cadena opaca segura para URLs.
+func (c Cursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor convierte una cadena generada por Encode en un Cursor.
+func DecodeCursor(value string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	cursor := &Cursor{}
+	if err := json.Unmarshal(raw, cursor); err != nil || len(cursor.Values) == 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+// Validate normaliza el límite y el orden y verifica que los parámetros sean coherentes.
+func (p *UserListParams) Validate() error {
+	if p.Limit == 0 {
+		p.Limit = DefaultPageLimit
+	}
+	if p.Limit < 0 || p.Limit > MaxPageLimit {
+		return NewValidationError("invalid_limit", "limit", fmt.Sprintf("el limit debe estar entre 1 y %d", MaxPageLimit))
+	}
+	if p.Offset < 0 {
+		return NewValidationError("invalid_offset", "offset", "el offset no puede ser negativo")
+	}
+	if p.Cursor != nil && p.Offset > 0 {
+		return NewValidationError("cursor_with_offset", "cursor", "no se puede usar cursor y offset al mismo tiempo")
+	}
+	if len(p.Sort) == 0 {
+		p.Sort = []SortField{{Field: "created_at", Column: "created_at", Desc: true}}
+	}
+	if p.Cursor != nil {
+		// Un cursor solo es válido con el mismo orden con el que se generó
+		if p.Cursor.Sort != FormatSort(p.OrderBy()) {
+			return ErrInvalidCursor
+		}
+		if _, err := p.CursorValues(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OrderBy devuelve el orden completo del listado, agregando id como desempate.
+func (p *UserListParams) OrderBy() []SortField {
+	order := append([]SortField{}, p.Sort...)
+	for _, f := range order {
+		if f.Field == "id" {
+			return order
+		}
+	}
+	// El desempate sigue la dirección del último criterio
+	desc := len(order) > 0 && order[len(order)-1].Desc
+	return append(order, SortField{Field: "id", Column: "id", Desc: desc})
+}
+
+// CursorValues convierte los valores del cursor al tipo de cada campo del orden.
+func (p *UserListParams) CursorValues() ([]any, error) {
+	order := p.OrderBy()
+	if p.Cursor == nil || len(p.Cursor.Values) != len(order) {
+		return nil, ErrInvalidCursor
+	}
+
+	values := make([]any, len(order))
+	for i, f := range order {
+		value, err := userFields[f.Field].parseValue(p.Cursor.Values[i])
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		values[i] = value
+	}
+	return values, nil
+}
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	created := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	user := &User{ID: 42, Name: "Ana", Age: 30, CreatedAt: created}
+	params := &UserListParams{Sort: []SortField{{Field: "age", Column: "age", Desc: true}, {Field: "created_at", Column: "created_at"}}}
+
+	cursor := NewCursor(params.OrderBy(), user)
+	decoded, err := DecodeCursor(cursor.Encode())
+	if err != nil {
+		t.Fatalf("DecodeCursor: %v", err)
+	}
+
+	params.Cursor = decoded
+	if err := params.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	values, err := params.CursorValues()
+	if err != nil {
+		t.Fatalf("CursorValues: %v", err)
+	}
+	if values[0] != 30 || !values[1].(time.Time).Equal(created) || values[2] != 42 {
+		t.Errorf("CursorValues = %v, se esperaba [30 %v 42]", values, created)
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"no es base64", "%%%"},
+		{"no es JSON", "bm8tanNvbg"},
+		{"sin valores", Cursor{Sort: "-created_at,-id"}.Encode()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeCursor(tt.value); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("DecodeCursor(%q) = %v, se esperaba ErrInvalidCursor", tt.value, err)
+			}
+		})
+	}
+}
+
+func TestUserListParamsValidate(t *testing.T) {
+	byAge := []SortField{{Field: "age", Column: "age"}}
+
+	tests := []struct {
+		name      string
+		params    UserListParams
+		wantErr   string
+		wantLimit int
+	}{
+		{name: "limit por defecto", params: UserListParams{}, wantLimit: DefaultPageLimit},
+		{name: "limit máximo", params: UserListParams{Limit: MaxPageLimit}, wantLimit: MaxPageLimit},
+		{name: "limit excedido", params: UserListParams{Limit: MaxPageLimit + 1}, wantErr: "invalid_limit"},
+		{name: "limit negativo", params: UserListParams{Limit: -1}, wantErr: "invalid_limit"},
+		{name: "offset negativo", params: UserListParams{Offset: -5}, wantErr: "invalid_offset"},
+		{
+			name:    "cursor con offset",
+			params:  UserListParams{Offset: 10, Cursor: &Cursor{Sort: "-created_at,-id", Values: []string{"x", "1"}}},
+			wantErr: "cursor_with_offset",
+		},
+		{
+			name:    "cursor de otro orden",
+			params:  UserListParams{Sort: byAge, Cursor: &Cursor{Sort: "-created_at,-id", Values: []string{"2024-01-01T00:00:00Z", "1"}}},
+			wantErr: "invalid_cursor",
+		},
+		{
+			name:    "cursor con valores de otro tipo",
+			params:  UserListParams{Sort: byAge, Cursor: &Cursor{Sort: "age,id", Values: []string{"treinta", "1"}}},
+			wantErr: "invalid_cursor",
+		},
+		{
+			name:    "cursor con valores de menos",
+			params:  UserListParams{Sort: byAge, Cursor: &Cursor{Sort: "age,id", Values: []string{"30"}}},
+			wantErr: "invalid_cursor",
+		},
+		{
+			name:      "cursor válido",
+			params:    UserListParams{Sort: byAge, Cursor: &Cursor{Sort: "age,id", Values: []string{"30", "7"}}},
+			wantLimit: DefaultPageLimit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := tt.params
+			err := params.Validate()
+			if tt.wantErr != "" {
+				var domainErr *DomainError
+				if !errors.As(err, &domainErr) || domainErr.Code != tt.wantErr {
+					t.Fatalf("Validate() = %v, se esperaba el código %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Validate() = %v", err)
+			}
+			if params.Limit != tt.wantLimit {
+				t.Errorf("Limit = %d, se esperaba %d", params.Limit, tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestOrderByAddsIDTieBreaker(t *testing.T) {
+	tests := []struct {
+		sort string
+		want string
+	}{
+		{"-created_at", "-created_at,-id"},
+		{"name", "name,id"},
+		{"-age,name", "-age,name,id"},
+		{"id,-age", "id,-age"},
+	}
+
+	for _, tt := range tests {
+		sort, err := ParseSort(tt.sort)
+		if err != nil {
+			t.Fatalf("ParseSort(%q): %v", tt.sort, err)
+		}
+		params := UserListParams{Sort: sort}
+		if got := FormatSort(params.OrderBy()); got != tt.want {
+			t.Errorf("OrderBy(%q) = %q, se esperaba %q", tt.sort, got, tt.want)
+		}
+	}
+}
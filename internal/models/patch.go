@@ -0,0 +1,198 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// UserPatch es una modificación parcial que se aplica sobre un usuario existente.
+type UserPatch interface {
+	ApplyTo(user *User) error
+}
+
+// PatchUserRequest es un JSON Merge Patch (RFC 7386) sobre un usuario.
+// Los campos son punteros para distinguir "no enviado" (nil) de un valor cero.
+type PatchUserRequest struct {
+	Name  *string `json:"name,omitempty"`
+	Email *string `json:"email,omitempty"`
+	Age   *int    `json:"age,omitempty"`
+}
+
+// JSONPatchOperation es una operación de un JSON Patch (RFC 6902).
+type JSONPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// JSONPatch es una lista de operaciones que se aplican en orden.
+type JSONPatch []JSONPatchOperation
+
+// UnmarshalJSON rechaza campos desconocidos o de solo lectura y los valores
+// null, que en un merge patch significan "eliminar" y ningún campo de User
+// se puede eliminar.
+func (p *PatchUserRequest) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	for key, raw := range fields {
+		if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			return fmt.Errorf("el campo %q no se puede eliminar", key)
+		}
+
+		var err error
+		switch key {
+		case "name":
+			p.Name = new(string)
+			err = json.Unmarshal(raw, p.Name)
+		case "email":
+			p.Email = new(string)
+			err = json.Unmarshal(raw, p.Email)
+		case "age":
+			p.Age = new(int)
+			err = json.Unmarshal(raw, p.Age)
+		default:
+			return fmt.Errorf("el campo %q no se puede modificar", key)
+		}
+		if err != nil {
+			return fmt.Errorf("valor inválido para el campo %q", key)
+		}
+	}
+
+	return nil
+}
+
+// ApplyTo copia sobre el usuario solo los campos enviados.
+func (p *PatchUserRequest) ApplyTo(user *User) error {
+	if p.Name != nil {
+		user.Name = *p.Name
+	}
+	if p.Email != nil {
+		user.Email = *p.Email
+	}
+	if p.Age != nil {
+		user.Age = *p.Age
+	}
+	return nil
+}
+
+// errPatchTestFailed indica que una operación "test" no coincidió con el estado actual.
+var errPatchTestFailed = errors.New("el valor actual no coincide")
+
+// ApplyTo ejecuta cada operación sobre el usuario. Si alguna falla se
+// devuelve el error y el llamador debe descartar el usuario modificado.
+func (p JSONPatch) ApplyTo(user *User) error {
+	for i, op := range p {
+		if err := op.apply(user); err != nil {
+			message := fmt.Sprintf("operación %d (%s %s): %v", i, op.Op, op.Path, err)
+			if errors.Is(err, errPatchTestFailed) {
+				return NewConflictError("patch_test_failed", message)
+			}
+			return NewValidationError("invalid_patch", op.Path, message)
+		}
+	}
+	return nil
+}
+
+func (op JSONPatchOperation) apply(user *User) error {
+	switch op.Op {
+	case "add", "replace":
+		if op.Value == nil {
+			return errors.New("la operación requiere value")
+		}
+		return setPatchField(user, op.Path, op.Value)
+	case "test":
+		current, err := getPatchField(user, op.Path)
+		if err != nil {
+			return err
+		}
+		if !jsonEqual(current, op.Value) {
+			return errPatchTestFailed
+		}
+		return nil
+	case "copy":
+		value, err := getPatchField(user, op.From)
+		if err != nil {
+			return err
+		}
+		return setPatchField(user, op.Path, value)
+	case "remove", "move":
+		return errors.New("los campos del usuario no se pueden eliminar")
+	default:
+		return errors.New("operación no soportada")
+	}
+}
+
+// patchField convierte un JSON Pointer ("/name") en el nombre del campo.
+func patchField(pointer string) (string, error) {
+	field, ok := strings.CutPrefix(pointer, "/")
+	if !ok {
+		return "", fmt.Errorf("ruta inválida %q", pointer)
+	}
+
+	switch field {
+	case "name", "email", "age":
+		return field, nil
+	case "id", "created_at", "updated_at":
+		return "", fmt.Errorf("el campo %q es de solo lectura", field)
+	default:
+		return "", fmt.Errorf("ruta no soportada %q", pointer)
+	}
+}
+
+func getPatchField(user *User, pointer string) (json.RawMessage, error) {
+	field, err := patchField(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	switch field {
+	case "name":
+		return json.Marshal(user.Name)
+	case "email":
+		return json.Marshal(user.Email)
+	default:
+		return json.Marshal(user.Age)
+	}
+}
+
+func setPatchField(user *User, pointer string, value json.RawMessage) error {
+	field, err := patchField(pointer)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+		return fmt.Errorf("el campo %q no puede ser null", field)
+	}
+
+	switch field {
+	case "name":
+		err = json.Unmarshal(value, &user.Name)
+	case "email":
+		err = json.Unmarshal(value, &user.Email)
+	default:
+		err = json.Unmarshal(value, &user.Age)
+	}
+	if err != nil {
+		return fmt.Errorf("valor inválido para %q", field)
+	}
+
+	return nil
+}
+
+// jsonEqual compara dos valores JSON ignorando diferencias de formato.
+func jsonEqual(a, b json.RawMessage) bool {
+	var va, vb any
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	ja, _ := json.Marshal(va)
+	jb, _ := json.Marshal(vb)
+	return bytes.Equal(ja, jb)
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPatchUserRequest(t *testing.T) {
+	base := User{ID: 1, Name: "Ana", Email: "ana@example.com", Age: 30}
+
+	tests := []struct {
+		name    string
+		body    string
+		want    User
+		wantErr string
+	}{
+		{name: "vacío", body: `{}`, want: base},
+		{name: "un campo", body: `{"name":"Ana María"}`, want: User{ID: 1, Name: "Ana María", Email: "ana@example.com", Age: 30}},
+		{name: "valor cero", body: `{"age":0}`, want: User{ID: 1, Name: "Ana", Email: "ana@example.com", Age: 0}},
+		{name: "varios campos", body: `{"email":"ana@test.dev","age":31}`, want: User{ID: 1, Name: "Ana", Email: "ana@test.dev", Age: 31}},
+		{name: "null", body: `{"name":null}`, wantErr: "no se puede eliminar"},
+		{name: "solo lectura", body: `{"id":2}`, wantErr: "no se puede modificar"},
+		{name: "desconocido", body: `{"password":"x"}`, wantErr: "no se puede modificar"},
+		{name: "tipo incorrecto", body: `{"age":"treinta"}`, wantErr: "valor inválido"},
+		{name: "no es un objeto", body: `[]`, wantErr: "cannot unmarshal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch PatchUserRequest
+			err := json.Unmarshal([]byte(tt.body), &patch)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Unmarshal(%s) = %v, se esperaba un error con %q", tt.body, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal(%s): %v", tt.body, err)
+			}
+
+			user := base
+			if err := patch.ApplyTo(&user); err != nil {
+				t.Fatalf("ApplyTo: %v", err)
+			}
+			if user != tt.want {
+				t.Errorf("usuario = %+v, se esperaba %+v", user, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONPatch(t *testing.T) {
+	base := User{ID: 1, Name: "Ana", Email: "ana@example.com", Age: 30}
+
+	tests := []struct {
+		name     string
+		body     string
+		want     User
+		wantKind error
+	}{
+		{
+			name: "replace y add",
+			body: `[{"op":"replace","path":"/name","value":"Eva"},{"op":"add","path":"/age","value":25}]`,
+			want: User{ID: 1, Name: "Eva", Email: "ana@example.com", Age: 25},
+		},
+		{
+			name: "test correcto",
+			body: `[{"op":"test","path":"/age","value":30},{"op":"replace","path":"/age","value":31}]`,
+			want: User{ID: 1, Name: "Ana", Email: "ana@example.com", Age: 31},
+		},
+		{
+			name: "copy",
+			body: `[{"op":"copy","from":"/email","path":"/name"}]`,
+			want: User{ID: 1, Name: "ana@example.com", Email: "ana@example.com", Age: 30},
+		},
+		{name: "test fallido", body: `[{"op":"test","path":"/name","value":"Eva"}]`, wantKind: ErrConflict},
+		{name: "remove", body: `[{"op":"remove","path":"/name"}]`, wantKind: ErrValidation},
+		{name: "move", body: `[{"op":"move","from":"/name","path":"/email"}]`, wantKind: ErrValidation},
+		{name: "sin value", body: `[{"op":"replace","path":"/name"}]`, wantKind: ErrValidation},
+		{name: "value null", body: `[{"op":"replace","path":"/name","value":null}]`, wantKind: ErrValidation},
+		{name: "solo lectura", body: `[{"op":"replace","path":"/id","value":2}]`, wantKind: ErrValidation},
+		{name: "ruta sin barra", body: `[{"op":"replace","path":"name","value":"Eva"}]`, wantKind: ErrValidation},
+		{name: "tipo incorrecto", body: `[{"op":"replace","path":"/age","value":"x"}]`, wantKind: ErrValidation},
+		{name: "operación desconocida", body: `[{"op":"merge","path":"/name","value":"Eva"}]`, wantKind: ErrValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch JSONPatch
+			if err := json.Unmarshal([]byte(tt.body), &patch); err != nil {
+				t.Fatalf("Unmarshal(%s): %v", tt.body, err)
+			}
+
+			user := base
+			err := patch.ApplyTo(&user)
+			if tt.wantKind != nil {
+				if !errors.Is(err, tt.wantKind) {
+					t.Fatalf("ApplyTo = %v, se esperaba %v", err, tt.wantKind)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyTo: %v", err)
+			}
+			if user != tt.want {
+				t.Errorf("usuario = %+v, se esperaba %+v", user, tt.want)
+			}
+		})
+	}
+}
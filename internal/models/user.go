@@ -1,18 +1,22 @@
 package models
 
 import (
-	"errors"
 	"regexp"
+	"strconv"
 	"time"
 )
 
 type User struct {
-	ID        int       `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Age       int       `json:"age"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	Email     string     `json:"email"`
+	Age       int        `json:"age"`
+	Version   int        `json:"version"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// PasswordHash solo se carga con UserRepository.GetPasswordHash y nunca se serializa.
+	PasswordHash string `json:"-"`
 }
 
 type CreateUserRequest struct {
@@ -28,21 +32,26 @@ type UpdateUserRequest struct {
 }
 
 // ErrInvalidEmailFormat es retornado cuando el formato de un email es inválido.
-var ErrInvalidEmailFormat = errors.New("el email no es válido")
+var ErrInvalidEmailFormat = NewValidationError("invalid_email", "email", "el email no es válido")
+
+// ETag devuelve la etiqueta de entidad HTTP que identifica la versión del usuario.
+func (u *User) ETag() string {
+	return `"` + strconv.Itoa(u.Version) + `"`
+}
 
 // Validaciones de negocio
 func (u *User) Validate() error {
 	if u.Name == "" {
-		return errors.New("el nombre es requerido")
+		return NewValidationError("name_required", "name", "el nombre es requerido")
 	}
 	if u.Email == "" {
-		return errors.New("el email es requerido")
+		return NewValidationError("email_required", "email", "el email es requerido")
 	}
 	if !IsValidEmail(u.Email) {
 		return ErrInvalidEmailFormat
 	}
 	if u.Age < 0 || u.Age > 150 {
-		return errors.New("la edad debe estar entre 0 y 150")
+		return NewValidationError("invalid_age", "age", "la edad debe estar entre 0 y 150")
 	}
 	return nil
 }
@@ -0,0 +1,254 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"pt-brm/internal/config"
+	"pt-brm/internal/database"
+	"pt-brm/internal/models"
+	"strings"
+	"time"
+)
+
+// Columnas en el orden que espera scanAPIKey.
+const apiKeyColumns = "id, name, prefix, salt, hash, scopes, expires_at, last_used_at, revoked_at, created_at, updated_at"
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *models.APIKey) (*models.APIKey, error)
+	List(ctx context.Context) ([]*models.APIKey, error)
+	GetByID(ctx context.Context, id int) (*models.APIKey, error)
+	GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error)
+	Revoke(ctx context.Context, id int) (*models.APIKey, error)
+	Rotate(ctx context.Context, id int, prefix, salt, hash string) (*models.APIKey, error)
+	TouchLastUsed(ctx context.Context, id int, at time.Time) error
+}
+
+// sqlAPIKeyRepository implementa APIKeyRepository sobre database/sql con el
+// mismo dialect que los repositorios de usuarios.
+type sqlAPIKeyRepository struct {
+	db      *database.DB
+	dialect sqlDialect
+}
+
+// NewAPIKeyRepository crea el repositorio de API keys que corresponde al
+// motor de la conexión.
+func NewAPIKeyRepository(db *database.DB) APIKeyRepository {
+	dialect := mysqlDialect
+	switch db.Driver() {
+	case config.DriverSQLite:
+		dialect = sqliteDialect
+	case config.DriverPostgres:
+		dialect = postgresDialect
+	}
+	return &sqlAPIKeyRepository{db: db, dialect: dialect}
+}
+
+func (r *sqlAPIKeyRepository) Create(ctx context.Context, key *models.APIKey) (*models.APIKey, error) {
+	ctx, cancel := r.db.WithTimeout(ctx, database.OpWrite)
+	defer cancel()
+
+	query := `
+		INSERT INTO api_keys (name, prefix, salt, hash, scopes, expires_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ` + r.dialect.now + `, ` + r.dialect.now + `)`
+	args := []any{key.Name, key.Prefix, key.Salt, key.Hash, strings.Join(key.Scopes, " "), utcOrNil(key.ExpiresAt)}
+
+	var created *models.APIKey
+	err := r.db.WithinTx(ctx, func(ctx context.Context) error {
+		var id int64
+		var err error
+		if r.dialect.returning {
+			err = r.db.Executor(ctx).QueryRowContext(ctx, query+" RETURNING id", args...).Scan(&id)
+		} else {
+			var result sql.Result
+			result, err = r.db.Executor(ctx).ExecContext(ctx, query, args...)
+			if err == nil {
+				id, err = result.LastInsertId()
+			}
+		}
+		if err != nil {
+			if translated := r.dialect.translateError(err); translated != err {
+				return translated
+			}
+			return fmt.Errorf("no se pudo crear la API key: %w", err)
+		}
+
+		created, err = r.GetByID(ctx, int(id))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (r *sqlAPIKeyRepository) List(ctx context.Context) ([]*models.APIKey, error) {
+	ctx, cancel := r.db.WithTimeout(ctx, database.OpRead)
+	defer cancel()
+
+	query := "SELECT " + apiKeyColumns + " FROM api_keys ORDER BY created_at DESC, id DESC"
+
+	rows, err := r.db.Executor(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo consultar las API keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []*models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo escanear la API key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar filas: %w", err)
+	}
+
+	return keys, nil
+}
+
+func (r *sqlAPIKeyRepository) GetByID(ctx context.Context, id int) (*models.APIKey, error) {
+	ctx, cancel := r.db.WithTimeout(ctx, database.OpRead)
+	defer cancel()
+
+	query := "SELECT " + apiKeyColumns + " FROM api_keys WHERE id = ?"
+	return r.get(ctx, query, id)
+}
+
+func (r *sqlAPIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	ctx, cancel := r.db.WithTimeout(ctx, database.OpRead)
+	defer cancel()
+
+	query := "SELECT " + apiKeyColumns + " FROM api_keys WHERE prefix = ?"
+	return r.get(ctx, query, prefix)
+}
+
+func (r *sqlAPIKeyRepository) get(ctx context.Context, query string, arg any) (*models.APIKey, error) {
+	key, err := scanAPIKey(r.db.Executor(ctx).QueryRowContext(ctx, query, arg))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("no se pudo obtener la API key: %w", err)
+	}
+	return key, nil
+}
+
+// Revoke marca la clave como revocada; revocar una clave ya revocada no la modifica.
+func (r *sqlAPIKeyRepository) Revoke(ctx context.Context, id int) (*models.APIKey, error) {
+	ctx, cancel := r.db.WithTimeout(ctx, database.OpWrite)
+	defer cancel()
+
+	query := `
+		UPDATE api_keys
+		SET revoked_at = ` + r.dialect.now + `, updated_at = ` + r.dialect.now + `
+		WHERE id = ? AND revoked_at IS NULL
+	`
+
+	var revoked *models.APIKey
+	err := r.db.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := r.db.Executor(ctx).ExecContext(ctx, query, id); err != nil {
+			return fmt.Errorf("no se pudo revocar la API key: %w", err)
+		}
+
+		var err error
+		revoked, err = r.GetByID(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return revoked, nil
+}
+
+// Rotate reemplaza el secreto de una clave activa; el anterior deja de
+// funcionar de inmediato.
+func (r *sqlAPIKeyRepository) Rotate(ctx context.Context, id int, prefix, salt, hash string) (*models.APIKey, error) {
+	ctx, cancel := r.db.WithTimeout(ctx, database.OpWrite)
+	defer cancel()
+
+	query := `
+		UPDATE api_keys
+		SET prefix = ?, salt = ?, hash = ?, last_used_at = NULL, updated_at = ` + r.dialect.now + `
+		WHERE id = ? AND revoked_at IS NULL
+	`
+
+	var rotated *models.APIKey
+	err := r.db.WithinTx(ctx, func(ctx context.Context) error {
+		result, err := r.db.Executor(ctx).ExecContext(ctx, query, prefix, salt, hash, id)
+		if err != nil {
+			if translated := r.dialect.translateError(err); translated != err {
+				return translated
+			}
+			return fmt.Errorf("no se pudo rotar la API key: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("no se pudieron obtener las filas afectadas: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			// O no existe, o está revocada
+			if _, err := r.GetByID(ctx, id); err != nil {
+				return err
+			}
+			return models.ErrAPIKeyRevoked
+		}
+
+		rotated, err = r.GetByID(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rotated, nil
+}
+
+// TouchLastUsed registra el último uso de la clave.
+func (r *sqlAPIKeyRepository) TouchLastUsed(ctx context.Context, id int, at time.Time) error {
+	ctx, cancel := r.db.WithTimeout(ctx, database.OpWrite)
+	defer cancel()
+
+	query := "UPDATE api_keys SET last_used_at = ? WHERE id = ?"
+	if _, err := r.db.Executor(ctx).ExecContext(ctx, query, at.UTC().Truncate(time.Second), id); err != nil {
+		return fmt.Errorf("no se pudo registrar el uso de la API key: %w", err)
+	}
+	return nil
+}
+
+func scanAPIKey(row rowScanner) (*models.APIKey, error) {
+	key := &models.APIKey{}
+	var scopes string
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		&key.Salt,
+		&key.Hash,
+		&scopes,
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+		&key.CreatedAt,
+		&key.UpdatedAt,
+	)
+	key.Scopes = strings.Fields(scopes)
+	return key, err
+}
+
+// utcOrNil pasa una fecha opcional a UTC, el formato en que se comparan las
+// fechas guardadas en SQLite.
+func utcOrNil(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
@@ -0,0 +1,480 @@
+package repositories
+
+import (
+	"context"
+	"maps"
+	"pt-brm/internal/models"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryUserRepository guarda los usuarios en memoria con la misma semántica
+// que MySQLUserRepository: email único entre los activos (sin distinguir
+// mayúsculas), borrado lógico, versión y timestamps. Sirve para ejercitar
+// services y handlers sin una base de datos.
+type MemoryUserRepository struct {
+	mu     sync.RWMutex
+	users  map[int]*models.User
+	nextID int
+	now    func() time.Time
+}
+
+func NewMemoryUserRepository() *MemoryUserRepository {
+	return &MemoryUserRepository{
+		users:  make(map[int]*models.User),
+		nextID: 1,
+		now:    time.Now,
+	}
+}
+
+// WithinTx ejecuta fn y, si falla, devuelve el repositorio al estado previo.
+// Las llamadas anidadas se comportan como savepoints. No aísla fn de otras
+// escrituras concurrentes: al revertir también se pierden esas escrituras.
+func (r *MemoryUserRepository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	r.mu.RLock()
+	snapshot, nextID := r.clone(), r.nextID
+	r.mu.RUnlock()
+
+	if err := fn(ctx); err != nil {
+		r.mu.Lock()
+		r.users, r.nextID = snapshot, nextID
+		r.mu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+func (r *MemoryUserRepository) Create(ctx context.Context, user *models.User) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.activeByEmail(user.Email, 0) != nil {
+		return nil, models.ErrEmailAlreadyExists
+	}
+
+	return copyUser(r.insert(user)), nil
+}
+
+func (r *MemoryUserRepository) GetAll(ctx context.Context) ([]*models.User, error) {
+	users := r.filter(&models.UserListParams{})
+	slices.SortFunc(users, func(a, b *models.User) int {
+		return models.CompareUsers(a, b, []models.SortField{{Field: "created_at", Desc: true}, {Field: "id", Desc: true}})
+	})
+	return users, nil
+}
+
+func (r *MemoryUserRepository) List(ctx context.Context, params *models.UserListParams) (*models.UserPage, error) {
+	page := &models.UserPage{
+		Limit:  params.Limit,
+		Offset: params.Offset,
+	}
+
+	users := r.filter(params)
+	page.Total = len(users)
+
+	order := params.OrderBy()
+	slices.SortFunc(users, func(a, b *models.User) int {
+		return models.CompareUsers(a, b, order)
+	})
+
+	// Paginación keyset: continuar justo después del último registro entregado
+	if params.Cursor != nil {
+		values, err := params.CursorValues()
+		if err != nil {
+			return nil, err
+		}
+		users = slices.DeleteFunc(users, func(user *models.User) bool {
+			return !afterCursor(user, order, values)
+		})
+	}
+
+	users = users[min(params.Offset, len(users)):]
+	if len(users) > params.Limit {
+		users = users[:params.Limit]
+		last := users[len(users)-1]
+		page.NextCursor = models.NewCursor(order, last).Encode()
+	}
+	page.Users = users
+
+	return page, nil
+}
+
+// Export recorre una copia de los usuarios, de modo que fn puede usar el
+// repositorio sin bloquearse.
+func (r *MemoryUserRepository) Export(ctx context.Context, params *models.UserListParams, fn func(user *models.User) error) error {
+	users := r.filter(params)
+	order := params.OrderBy()
+	slices.SortFunc(users, func(a, b *models.User) int {
+		return models.CompareUsers(a, b, order)
+	})
+
+	for _, user := range users {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(user); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *MemoryUserRepository) GetByID(ctx context.Context, id int) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user := r.active(id)
+	if user == nil {
+		return nil, models.ErrUserNotFound
+	}
+	return copyUser(user), nil
+}
+
+// Update guarda los cambios solo si la versión almacenada sigue siendo
+// user.Version (concurrencia optimista) e incrementa la versión.
+func (r *MemoryUserRepository) Update(ctx context.Context, id int, user *models.User) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, err := r.update(id, user)
+	if err != nil {
+		return nil, err
+	}
+	return copyUser(stored), nil
+}
+
+// Delete marca el usuario como eliminado (soft delete); si version es distinto
+// de 0 solo lo elimina cuando la versión almacenada coincide.
+func (r *MemoryUserRepository) Delete(ctx context.Context, id int, version int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user := r.active(id)
+	if user == nil {
+		return models.ErrUserNotFound
+	}
+	if version != 0 && user.Version != version {
+		return models.ErrPreconditionFailed
+	}
+
+	r.softDelete(user)
+	return nil
+}
+
+func (r *MemoryUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user := r.activeByEmail(email, 0)
+	if user == nil {
+		return nil, models.ErrUserNotFound
+	}
+	return copyUser(user), nil
+}
+
+func (r *MemoryUserRepository) GetPasswordHash(ctx context.Context, id int) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user := r.active(id)
+	if user == nil {
+		return "", models.ErrUserNotFound
+	}
+	return user.PasswordHash, nil
+}
+
+func (r *MemoryUserRepository) SetPasswordHash(ctx context.Context, id int, hash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user := r.active(id)
+	if user == nil {
+		return models.ErrUserNotFound
+	}
+
+	updated := *user
+	updated.PasswordHash = hash
+	r.users[id] = &updated
+	return nil
+}
+
+// Restore quita la marca de eliminado de un usuario. Falla con conflicto si
+// su email fue tomado por otro usuario mientras estaba eliminado.
+func (r *MemoryUserRepository) Restore(ctx context.Context, id int) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, models.ErrUserNotFound
+	}
+	if user.DeletedAt == nil {
+		return nil, models.ErrUserNotDeleted
+	}
+	if r.activeByEmail(user.Email, id) != nil {
+		return nil, models.ErrEmailAlreadyExists
+	}
+
+	restored := *user
+	restored.DeletedAt = nil
+	restored.Version++
+	restored.UpdatedAt = r.now()
+	r.users[id] = &restored
+
+	return copyUser(&restored), nil
+}
+
+// PurgeDeleted elimina definitivamente los usuarios marcados como eliminados antes de la fecha indicada.
+func (r *MemoryUserRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for id, user := range r.users {
+		if user.DeletedAt != nil && user.DeletedAt.Before(before) {
+			delete(r.users, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// GetByIDs devuelve los usuarios activos con los ids indicados, indexados por id.
+func (r *MemoryUserRepository) GetByIDs(ctx context.Context, ids []int) (map[int]*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make(map[int]*models.User, len(ids))
+	for _, id := range ids {
+		if user := r.active(id); user != nil {
+			users[id] = copyUser(user)
+		}
+	}
+
+	return users, nil
+}
+
+// GetByEmails devuelve los usuarios activos con los emails indicados, indexados
+// por email en minúsculas.
+func (r *MemoryUserRepository) GetByEmails(ctx context.Context, emails []string) (map[string]*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make(map[string]*models.User, len(emails))
+	for _, email := range emails {
+		if user := r.activeByEmail(email, 0); user != nil {
+			users[strings.ToLower(user.Email)] = copyUser(user)
+		}
+	}
+
+	return users, nil
+}
+
+// CreateMany crea los usuarios y devuelve, alineados con la entrada, los
+// creados y el error de cada uno. Si atomic es true y alguno falla no se crea ninguno.
+func (r *MemoryUserRepository) CreateMany(ctx context.Context, users []*models.User, atomic bool) ([]*models.User, []error, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshot, nextID := r.clone(), r.nextID
+	created := make([]*models.User, len(users))
+	errs := make([]error, len(users))
+	failed := false
+
+	for i, user := range users {
+		if r.activeByEmail(user.Email, 0) != nil {
+			errs[i], failed = models.ErrEmailAlreadyExists, true
+			continue
+		}
+		created[i] = copyUser(r.insert(user))
+	}
+
+	if atomic && failed {
+		r.users, r.nextID = snapshot, nextID
+		return make([]*models.User, len(users)), errs, nil
+	}
+
+	return created, errs, nil
+}
+
+// UpdateMany actualiza cada usuario verificando su versión. Devuelve los
+// usuarios actualizados y el error de cada uno, alineados con la entrada.
+func (r *MemoryUserRepository) UpdateMany(ctx context.Context, users []*models.User, atomic bool) ([]*models.User, []error, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshot := r.clone()
+	updated := make([]*models.User, len(users))
+	errs := make([]error, len(users))
+	failed := false
+
+	for i, user := range users {
+		stored, err := r.update(user.ID, user)
+		if err != nil {
+			errs[i], failed = err, true
+			continue
+		}
+		updated[i] = copyUser(stored)
+	}
+
+	if atomic && failed {
+		r.users = snapshot
+		return make([]*models.User, len(users)), errs, nil
+	}
+
+	return updated, errs, nil
+}
+
+// DeleteMany marca como eliminados los usuarios indicados. Devuelve el error
+// de cada id, alineado con la entrada.
+func (r *MemoryUserRepository) DeleteMany(ctx context.Context, ids []int, atomic bool) ([]error, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	errs := make([]error, len(ids))
+	failed := false
+
+	// Igual que el SELECT ... FOR UPDATE, la existencia se evalúa antes de eliminar
+	var found []*models.User
+	for i, id := range ids {
+		user := r.active(id)
+		if user == nil {
+			errs[i], failed = models.ErrUserNotFound, true
+			continue
+		}
+		found = append(found, user)
+	}
+
+	if atomic && failed {
+		return errs, nil
+	}
+
+	for _, user := range found {
+		// Un id repetido en la solicitud se elimina una sola vez
+		if current := r.active(user.ID); current != nil {
+			r.softDelete(current)
+		}
+	}
+
+	return errs, nil
+}
+
+// filter devuelve copias de los usuarios que cumplen los filtros de params.
+func (r *MemoryUserRepository) filter(params *models.UserListParams) []*models.User {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]*models.User, 0, len(r.users))
+	for _, user := range r.users {
+		if user.DeletedAt != nil && !params.IncludeDeleted {
+			continue
+		}
+		if !slices.ContainsFunc(params.Filters, func(f models.Filter) bool { return !f.Matches(user) }) {
+			users = append(users, copyUser(user))
+		}
+	}
+
+	return users
+}
+
+func (r *MemoryUserRepository) insert(user *models.User) *models.User {
+	now := r.now()
+	stored := &models.User{
+		ID:        r.nextID,
+		Name:      user.Name,
+		Email:     user.Email,
+		Age:       user.Age,
+		Version:   1,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	r.users[stored.ID] = stored
+	r.nextID++
+
+	return stored
+}
+
+func (r *MemoryUserRepository) update(id int, user *models.User) (*models.User, error) {
+	stored := r.active(id)
+	if stored == nil {
+		return nil, models.ErrUserNotFound
+	}
+	if stored.Version != user.Version {
+		return nil, models.ErrPreconditionFailed
+	}
+	if r.activeByEmail(user.Email, id) != nil {
+		return nil, models.ErrEmailAlreadyExists
+	}
+
+	// Se reemplaza el puntero para no alterar las copias tomadas por WithinTx
+	updated := *stored
+	updated.Name = user.Name
+	updated.Email = user.Email
+	updated.Age = user.Age
+	updated.Version++
+	updated.UpdatedAt = r.now()
+	r.users[id] = &updated
+
+	return &updated, nil
+}
+
+func (r *MemoryUserRepository) softDelete(user *models.User) {
+	deleted := *user
+	now := r.now()
+	deleted.DeletedAt = &now
+	deleted.Version++
+	r.users[user.ID] = &deleted
+}
+
+func (r *MemoryUserRepository) active(id int) *models.User {
+	if user, ok := r.users[id]; ok && user.DeletedAt == nil {
+		return user
+	}
+	return nil
+}
+
+// activeByEmail busca un usuario activo con el email, ignorando el id exceptID.
+func (r *MemoryUserRepository) activeByEmail(email string, exceptID int) *models.User {
+	for _, user := range r.users {
+		if user.DeletedAt == nil && user.ID != exceptID && strings.EqualFold(user.Email, email) {
+			return user
+		}
+	}
+	return nil
+}
+
+// clone copia el mapa; los usuarios se comparten porque nunca se modifican en el lugar.
+func (r *MemoryUserRepository) clone() map[int]*models.User {
+	return maps.Clone(r.users)
+}
+
+// afterCursor indica si el usuario va después de la posición del cursor según el orden.
+func afterCursor(user *models.User, order []models.SortField, values []any) bool {
+	for i, f := range order {
+		c := user.CompareField(f.Field, values[i])
+		if f.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c > 0
+		}
+	}
+	return false
+}
+
+// copyUser copia el usuario sin el hash de la contraseña, que solo se entrega
+// con GetPasswordHash, igual que en los repositorios SQL.
+func copyUser(user *models.User) *models.User {
+	copied := *user
+	copied.PasswordHash = ""
+	if user.DeletedAt != nil {
+		deletedAt := *user.DeletedAt
+		copied.DeletedAt = &deletedAt
+	}
+	return &copied
+}
@@ -0,0 +1,13 @@
+package repositories_test
+
+import (
+	"pt-brm/internal/repositories"
+	"pt-brm/internal/repositories/repotest"
+	"testing"
+)
+
+func TestMemoryUserRepository(t *testing.T) {
+	repotest.TestUserRepository(t, func(t *testing.T) repositories.UserRepository {
+		return repositories.NewMemoryUserRepository()
+	})
+}
@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"pt-brm/internal/models"
+	"regexp"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Códigos de error de MySQL que se traducen a errores del dominio.
+const (
+	mysqlErrDuplicateEntry    = 1062
+	mysqlErrNoReferencedRow   = 1452
+	mysqlErrDataTooLong       = 1406
+	mysqlErrNoDefaultForField = 1364
+)
+
+var (
+	// Duplicate entry 'a@b.com' for key 'users.email'
+	duplicateKeyPattern = regexp.MustCompile(`for key '([^']+)'`)
+	// ... CONSTRAINT `fk` FOREIGN KEY (`role_id`) REFERENCES ...
+	foreignKeyPattern = regexp.MustCompile("FOREIGN KEY \\(`([^`]+)`\\)")
+	// Data too long for column 'name' at row 1 / Field 'age' doesn't have a default value
+	columnPattern = regexp.MustCompile(`(?:column|Field) '([^']+)'`)
+)
+
+// Índices únicos de la tabla users y la columna que protegen.
+var uniqueKeyColumns = map[string]string{
+	"email":                 "email",
+	"users_email_key":       "email",
+	"uq_users_active_email": "email",
+}
+
+// translateMySQLError convierte los errores de restricciones de MySQL en
+// errores del dominio que indican la columna afectada. Cualquier otro error
+// se devuelve sin cambios.
+func translateMySQLError(err error) error {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return err
+	}
+
+	switch mysqlErr.Number {
+	case mysqlErrDuplicateEntry:
+		column := duplicateKeyColumn(mysqlErr.Message)
+		if column == "email" {
+			return models.ErrEmailAlreadyExists
+		}
+		conflict := models.NewConflictError("duplicate_value", fmt.Sprintf("ya existe un registro con el mismo valor en %s", column))
+		conflict.Field = column
+		return conflict
+	case mysqlErrNoReferencedRow:
+		column := submatch(foreignKeyPattern, mysqlErr.Message)
+		return models.NewValidationError("invalid_reference", column, fmt.Sprintf("el valor de %s hace referencia a un registro inexistente", column))
+	case mysqlErrDataTooLong:
+		column := submatch(columnPattern, mysqlErr.Message)
+		return models.NewValidationError("value_too_long", column, fmt.Sprintf("el valor de %s es demasiado largo", column))
+	case mysqlErrNoDefaultForField:
+		column := submatch(columnPattern, mysqlErr.Message)
+		return models.NewValidationError("field_required", column, fmt.Sprintf("el campo %s es requerido", column))
+	}
+
+	return err
+}
+
+// duplicateKeyColumn obtiene la columna a partir del nombre del índice
+// ("users.email" en MySQL 8, "email" en versiones anteriores).
+func duplicateKeyColumn(message string) string {
+	key := submatch(duplicateKeyPattern, message)
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		key = key[i+1:]
+	}
+	if column, ok := uniqueKeyColumns[key]; ok {
+		return column
+	}
+	return key
+}
+
+func submatch(pattern *regexp.Regexp, message string) string {
+	match := pattern.FindStringSubmatch(message)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"pt-brm/internal/models"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestTranslateMySQLError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantKind  error
+		wantCode  string
+		wantField string
+	}{
+		{
+			name:      "email duplicado en MySQL 8",
+			err:       &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'a@b.com' for key 'users.email'"},
+			wantKind:  models.ErrConflict,
+			wantCode:  "email_already_exists",
+			wantField: "email",
+		},
+		{
+			name:      "email duplicado en versiones anteriores",
+			err:       fmt.Errorf("insert: %w", &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'a@b.com' for key 'email'"}),
+			wantKind:  models.ErrConflict,
+			wantCode:  "email_already_exists",
+			wantField: "email",
+		},
+		{
+			name:      "otro índice único",
+			err:       &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'x' for key 'users.uq_users_code'"},
+			wantKind:  models.ErrConflict,
+			wantCode:  "duplicate_value",
+			wantField: "uq_users_code",
+		},
+		{
+			name:      "clave foránea",
+			err:       &mysql.MySQLError{Number: 1452, Message: "Cannot add or update a child row: a foreign key constraint fails (`db`.`users`, CONSTRAINT `fk_role` FOREIGN KEY (`role_id`) REFERENCES `roles` (`id`))"},
+			wantKind:  models.ErrValidation,
+			wantCode:  "invalid_reference",
+			wantField: "role_id",
+		},
+		{
+			name:      "valor demasiado largo",
+			err:       &mysql.MySQLError{Number: 1406, Message: "Data too long for column 'name' at row 1"},
+			wantKind:  models.ErrValidation,
+			wantCode:  "value_too_long",
+			wantField: "name",
+		},
+		{
+			name:      "campo sin valor por defecto",
+			err:       &mysql.MySQLError{Number: 1364, Message: "Field 'age' doesn't have a default value"},
+			wantKind:  models.ErrValidation,
+			wantCode:  "field_required",
+			wantField: "age",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := translateMySQLError(tt.err)
+
+			var domainErr *models.DomainError
+			if !errors.Is(err, tt.wantKind) || !errors.As(err, &domainErr) {
+				t.Fatalf("translateMySQLError = %v, se esperaba %v", err, tt.wantKind)
+			}
+			if domainErr.Code != tt.wantCode || domainErr.Field != tt.wantField {
+				t.Errorf("code=%q field=%q, se esperaba code=%q field=%q", domainErr.Code, domainErr.Field, tt.wantCode, tt.wantField)
+			}
+		})
+	}
+
+	t.Run("errores sin traducir", func(t *testing.T) {
+		others := []error{
+			errors.New("Error 1"),
+			&mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"},
+		}
+		for _, err := range others {
+			if got := translateMySQLError(err); got != err {
+				t.Errorf("translateMySQLError(%v) = %v, se esperaba el mismo error", err, got)
+			}
+		}
+	})
+}
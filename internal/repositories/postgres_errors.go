@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"pt-brm/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// Códigos SQLSTATE de Postgres que se traducen a errores del dominio.
+const (
+	pgErrUniqueViolation     = "23505"
+	pgErrForeignKeyViolation = "23503"
+	pgErrNotNullViolation    = "23502"
+	pgErrStringTooLong       = "22001"
+)
+
+// translatePostgresError convierte los errores de restricciones de Postgres
+// en los mismos errores del dominio que translateMySQLError. Cualquier otro
+// error se devuelve sin cambios.
+func translatePostgresError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	switch pqErr.Code {
+	case pgErrUniqueViolation:
+		column, ok := uniqueKeyColumns[pqErr.Constraint]
+		if !ok {
+			column = pqErr.Column
+		}
+		if column == "email" {
+			return models.ErrEmailAlreadyExists
+		}
+		conflict := models.NewConflictError("duplicate_value", fmt.Sprintf("ya existe un registro con el mismo valor en %s", column))
+		conflict.Field = column
+		return conflict
+	case pgErrForeignKeyViolation:
+		column := pqErr.Column
+		return models.NewValidationError("invalid_reference", column, fmt.Sprintf("el valor de %s hace referencia a un registro inexistente", column))
+	case pgErrStringTooLong:
+		// Postgres no informa la columna en este error
+		return models.NewValidationError("value_too_long", "", "un valor es demasiado largo")
+	case pgErrNotNullViolation:
+		column := pqErr.Column
+		return models.NewValidationError("field_required", column, fmt.Sprintf("el campo %s es requerido", column))
+	}
+
+	return err
+}
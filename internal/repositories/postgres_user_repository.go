@@ -0,0 +1,27 @@
+package repositories
+
+import "pt-brm/internal/database"
+
+type PostgresUserRepository struct {
+	*sqlUserRepository
+}
+
+func NewPostgresUserRepository(db *database.DB) UserRepository {
+	return &PostgresUserRepository{
+		sqlUserRepository: &sqlUserRepository{
+			db:      db,
+			dialect: postgresDialect,
+		},
+	}
+}
+
+var postgresDialect = sqlDialect{
+	now:             "NOW()",
+	like:            " ILIKE ?",
+	forUpdate:       " FOR UPDATE",
+	returning:       true,
+	abortsTxOnError: true,
+	translateError:  translatePostgresError,
+	// Usa el índice único sobre LOWER(email)
+	lower: "LOWER",
+}
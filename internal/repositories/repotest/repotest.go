@@ -0,0 +1,702 @@
+// Package repotest contiene el contrato que debe cumplir toda implementación
+// de repositories.UserRepository. Cada backend lo ejecuta desde sus propias
+// pruebas:
+//
+//	func TestMemoryUserRepository(t *testing.T) {
+//		repotest.TestUserRepository(t, func(t *testing.T) repositories.UserRepository {
+//			return repositories.NewMemoryUserRepository()
+//		})
+//	}
+//
+// Los backends SQL se prueban igual sobre una base vacía por caso; ver
+// sqlite_user_repository_test.go, que usa SQLite en memoria.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pt-brm/internal/models"
+	"pt-brm/internal/repositories"
+	"testing"
+	"time"
+)
+
+// Factory devuelve un repositorio vacío para cada caso del contrato.
+type Factory func(t *testing.T) repositories.UserRepository
+
+// TestUserRepository ejecuta el contrato completo contra los repositorios que crea newRepo.
+func TestUserRepository(t *testing.T, newRepo Factory) {
+	cases := []struct {
+		name string
+		fn   func(t *testing.T, repo repositories.UserRepository)
+	}{
+		{"Create", testCreate},
+		{"CreateDuplicateEmail", testCreateDuplicateEmail},
+		{"GetByIDNotFound", testGetByIDNotFound},
+		{"GetByEmail", testGetByEmail},
+		{"Update", testUpdate},
+		{"UpdateStaleVersion", testUpdateStaleVersion},
+		{"UpdateDuplicateEmail", testUpdateDuplicateEmail},
+		{"UpdateNotFound", testUpdateNotFound},
+		{"Delete", testDelete},
+		{"DeleteStaleVersion", testDeleteStaleVersion},
+		{"DeleteFreesEmail", testDeleteFreesEmail},
+		{"Restore", testRestore},
+		{"RestoreEmailTaken", testRestoreEmailTaken},
+		{"PurgeDeleted", testPurgeDeleted},
+		{"PurgeDeletedNonUTC", testPurgeDeletedNonUTC},
+		{"ListFiltersAndTotal", testListFiltersAndTotal},
+		{"ListCursorPagination", testListCursorPagination},
+		{"ListIncludeDeleted", testListIncludeDeleted},
+		{"GetByIDs", testGetByIDs},
+		{"GetByEmails", testGetByEmails},
+		{"CreateManyAtomic", testCreateManyAtomic},
+		{"CreateManyBestEffort", testCreateManyBestEffort},
+		{"UpdateMany", testUpdateMany},
+		{"DeleteMany", testDeleteMany},
+		{"Export", testExport},
+		{"PasswordHash", testPasswordHash},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.fn(t, newRepo(t))
+		})
+	}
+}
+
+func testCreate(t *testing.T, repo repositories.UserRepository) {
+	ctx := context.Background()
+	before := time.Now().Add(-time.Second)
+
+	user := mustCreate(t, repo, "Ana", "ana@example.com", 30)
+
+	if user.ID <= 0 {
+		t.Errorf("id = %d, se esperaba un id positivo", user.ID)
+	}
+	if user.Name != "Ana" || user.Email != "ana@example.com" || user.Age != 30 {
+		t.Errorf("usuario creado = %+v, no coincide con los datos enviados", user)
+	}
+	if user.Version != 1 {
+		t.Errorf("version = %d, se esperaba 1", user.Version)
+	}
+	if user.CreatedAt.Before(before) || user.UpdatedAt.Before(before) {
+		t.Errorf("timestamps = %v / %v, se esperaban posteriores a %v", user.CreatedAt, user.UpdatedAt, before)
+	}
+	if user.DeletedAt != nil {
+		t.Errorf("deleted_at = %v, se esperaba nil", user.DeletedAt)
+	}
+
+	found, err := repo.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if found.Email != user.Email || found.Version != user.Version {
+		t.Errorf("GetByID = %+v, se esperaba %+v", found, user)
+	}
+}
+
+func testCreateDuplicateEmail(t *testing.T, repo repositories.UserRepository) {
+	mustCreate(t, repo, "Ana", "ana@example.com", 30)
+
+	_, err := repo.Create(context.Background(), &models.User{Name: "Otra", Email: "ana@example.com", Age: 25})
+	assertIs(t, err, models.ErrEmailAlreadyExists)
+}
+
+func testGetByIDNotFound(t *testing.T, repo repositories.UserRepository) {
+	_, err := repo.GetByID(context.Background(), 999999)
+	assertIs(t, err, models.ErrUserNotFound)
+}
+
+func testGetByEmail(t *testing.T, repo repositories.UserRepository) {
+	ctx := context.Background()
+	user := mustCreate(t, repo, "Ana", "ana@example.com", 30)
+
+	found, err := repo.GetByEmail(ctx, "ana@example.com")
+	if err != nil {
+		t.Fatalf("GetByEmail: %v", err)
+	}
+	if found.ID != user.ID {
+		t.Errorf("GetByEmail devolvió el id %d, se esperaba %d", found.ID, user.ID)
+	}
+
+	// El email se compara sin distinguir mayúsculas en todos los motores
+	for _, email := range []string{"Ana@Example.com", "ANA@EXAMPLE.COM"} {
+		found, err := repo.GetByEmail(ctx, email)
+		if err != nil {
+			t.Fatalf("GetByEmail(%q): %v", email, err)
+		}
+		if found.ID != user.ID {
+			t.Errorf("GetByEmail(%q) devolvió el id %d, se esperaba %d", email, found.ID, user.ID)
+		}
+	}
+
+	_, err = repo.GetByEmail(ctx, "nadie@example.com")
+	assertIs(t, err, models.ErrUserNotFound)
+}
+
+func testUpdate(t *testing.T, repo repositories.UserRepository) {
+	user := mustCreate(t, repo, "Ana", "ana@example.com", 30)
+
+	user.Name = "Ana María"
+	user.Age = 31
+	updated, err := repo.Update(context.Background(), user.ID, user)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	if updated.Name != "Ana María" || updated.Age != 31 {
+		t.Errorf("usuario actualizado = %+v, no refleja los cambios", updated)
+	}
+	if updated.Version != user.Version+1 {
+		t.Errorf("version = %d, se esperaba %d", updated.Version, user.Version+1)
+	}
+	if updated.UpdatedAt.Before(user.UpdatedAt) {
+		t.Errorf("updated_at = %v, anterior al original %v", updated.UpdatedAt, user.UpdatedAt)
+	}
+	if !updated.CreatedAt.Equal(user.CreatedAt) {
+		t.Errorf("created_at cambió de %v a %v", user.CreatedAt, updated.CreatedAt)
+	}
+}
+
+func testUpdateStaleVersion(t *testing.T, repo repositories.UserRepository) {
+	user := mustCreate(t, repo, "Ana", "ana@example.com", 30)
+
+	user.Version++
+	_, err := repo.Update(context.Background(), user.ID, user)
+	assertIs(t, err, models.ErrPreconditionFailed)
+}
+
+func testUpdateDuplicateEmail(t *testing.T, repo repositories.UserRepository) {
+	mustCreate(t, repo, "Ana", "ana@example.com", 30)
+	user := mustCreate(t, repo, "Luis", "luis@example.com", 40)
+
+	user.Email = "ana@example.com"
+	_, err := repo.Update(context.Background(), user.ID, user)
+	assertIs(t, err, models.ErrEmailAlreadyExists)
+}
+
+func testUpdateNotFound(t *testing.T, repo repositories.UserRepository) {
+	_, err := repo.Update(context.Background(), 999999, &models.User{Name: "Ana", Email: "ana@example.com", Age: 30, Version: 1})
+	assertIs(t, err, models.ErrUserNotFound)
+}
+
+func testDelete(t *testing.T, repo repositories.UserRepository) {
+	ctx := context.Background()
+	user := mustCreate(t, repo, "Ana", "ana@example.com", 30)
+
+	if err := repo.Delete(ctx, user.ID, user.Version); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	_, err := repo.GetByID(ctx, user.ID)
+	assertIs(t, err, models.ErrUserNotFound)
+
+	// Eliminar de nuevo o eliminar uno inexistente es "no encontrado"
+	assertIs(t, repo.Delete(ctx, user.ID, 0), models.ErrUserNotFound)
+	assertIs(t, repo.Delete(ctx, 999999, 0), models.ErrUserNotFound)
+}
+
+func testDeleteStaleVersion(t *testing.T, repo repositories.UserRepository) {
+	user := mustCreate(t, repo, "Ana", "ana@example.com", 30)
+
+	assertIs(t, repo.Delete(context.Background(), user.ID, user.Version+1), models.ErrPreconditionFailed)
+}
+
+func testDeleteFreesEmail(t *testing.T, repo repositories.UserRepository) {
+	user := mustCreate(t, repo, "Ana", "ana@example.com", 30)
+
+	if err := repo.Delete(context.Background(), user.ID, 0); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	mustCreate(t, repo, "Ana", "ana@example.com", 30)
+}
+
+func testRestore(t *testing.T, repo repositories.UserRepository) {
+	ctx := context.Background()
+	user := mustCreate(t, repo, "Ana", "ana@example.com", 30)
+
+	_, err := repo.Restore(ctx, user.ID)
+	assertIs(t, err, models.ErrUserNotDeleted)
+
+	if err := repo.Delete(ctx, user.ID, 0); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	restored, err := repo.Restore(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if restored.DeletedAt != nil {
+		t.Errorf("deleted_at = %v, se esperaba nil", restored.DeletedAt)
+	}
+	if restored.Version != user.Version+2 {
+		t.Errorf("version = %d, se esperaba %d", restored.Version, user.Version+2)
+	}
+
+	_, err = repo.Restore(ctx, 999999)
+	assertIs(t, err, models.ErrUserNotFound)
+}
+
+func testRestoreEmailTaken(t *testing.T, repo repositories.UserRepository) {
+	ctx := context.Background()
+	user := mustCreate(t, repo, "Ana", "ana@example.com", 30)
+
+	if err := repo.Delete(ctx, user.ID, 0); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	mustCreate(t, repo, "Otra Ana", "ana@example.com", 25)
+
+	_, err := repo.Restore(ctx, user.ID)
+	assertIs(t, err, models.ErrEmailAlreadyExists)
+}
+
+func testPurgeDeleted(t *testing.T, repo repositories.UserRepository) {
+	ctx := context.Background()
+	deleted := mustCreate(t, repo, "Ana", "ana@example.com", 30)
+	active := mustCreate(t, repo, "Luis", "luis@example.com", 40)
+
+	if err := repo.Delete(ctx, deleted.ID, 0); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	// Nada fue eliminado antes de hace una hora
+	purged, err := repo.PurgeDeleted(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("PurgeDeleted: %v", err)
+	}
+	if purged != 0 {
+		t.Errorf("se purgaron %d usuarios, se esperaban 0", purged)
+	}
+
+	purged, err = repo.PurgeDeleted(ctx, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("PurgeDeleted: %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("se purgaron %d usuarios, se esperaba 1", purged)
+	}
+
+	_, err = repo.Restore(ctx, deleted.ID)
+	assertIs(t, err, models.ErrUserNotFound)
+
+	if _, err := repo.GetByID(ctx, active.ID); err != nil {
+		t.Errorf("el usuario activo no debía purgarse: %v", err)
+	}
+}
+
+// testPurgeDeletedNonUTC comprueba que la fecha de corte se compare como
+// instante y no según la zona horaria con la que llega.
+func testPurgeDeletedNonUTC(t *testing.T, repo repositories.UserRepository) {
+	ctx := context.Background()
+	deleted := mustCreate(t, repo, "Ana", "ana@example.com", 30)
+	if err := repo.Delete(ctx, deleted.ID, 0); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	// Hace una hora, expresado en una zona adelantada respecto de UTC
+	ahead := time.FixedZone("UTC+14", 14*60*60)
+	purged, err := repo.PurgeDeleted(ctx, time.Now().Add(-time.Hour).In(ahead))
+	if err != nil {
+		t.Fatalf("PurgeDeleted: %v", err)
+	}
+	if purged != 0 {
+		t.Errorf("se purgaron %d usuarios, se esperaban 0", purged)
+	}
+
+	// Dentro de una hora, expresado en una zona atrasada respecto de UTC
+	behind := time.FixedZone("UTC-12", -12*60*60)
+	purged, err = repo.PurgeDeleted(ctx, time.Now().Add(time.Hour).In(behind))
+	if err != nil {
+		t.Fatalf("PurgeDeleted: %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("se purgaron %d usuarios, se esperaba 1", purged)
+	}
+}
+
+func testListFiltersAndTotal(t *testing.T, repo repositories.UserRepository) {
+	mustCreate(t, repo, "Ana", "ana@example.com", 30)
+	mustCreate(t, repo, "Luis", "luis@test.org", 17)
+	mustCreate(t, repo, "Mariana", "mariana@example.com", 45)
+
+	cases := []struct {
+		filters []string
+		want    []string
+	}{
+		{[]string{"age>=18"}, []string{"Ana", "Mariana"}},
+		{[]string{"name~=ana"}, []string{"Ana", "Mariana"}},
+		{[]string{"email@=example.com", "age<40"}, []string{"Ana"}},
+		{[]string{"name!=Luis"}, []string{"Ana", "Mariana"}},
+		{[]string{"name!=luis"}, []string{"Ana", "Mariana"}},
+		{[]string{"email=ANA@Example.com"}, []string{"Ana"}},
+	}
+
+	for _, tc := range cases {
+		params := listParams(t, "name", tc.filters...)
+		page, err := repo.List(context.Background(), params)
+		if err != nil {
+			t.Fatalf("List %v: %v", tc.filters, err)
+		}
+
+		got := names(page.Users)
+		if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+			t.Errorf("List %v = %v, se esperaba %v", tc.filters, got, tc.want)
+		}
+		if page.Total != len(tc.want) {
+			t.Errorf("List %v: total = %d, se esperaba %d", tc.filters, page.Total, len(tc.want))
+		}
+	}
+}
+
+func testListCursorPagination(t *testing.T, repo repositories.UserRepository) {
+	ctx := context.Background()
+	var want []string
+	for i := range 7 {
+		// Edades repetidas para ejercitar el desempate por id
+		user := mustCreate(t, repo, fmt.Sprintf("Usuario %d", i), fmt.Sprintf("usuario%d@example.com", i), 20+i%3)
+		want = append(want, user.Name)
+	}
+
+	params := listParams(t, "-age")
+	params.Limit = 3
+
+	var got []string
+	for pages := 0; ; pages++ {
+		if pages > len(want) {
+			t.Fatal("la paginación no termina")
+		}
+
+		page, err := repo.List(ctx, params)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if page.Total != len(want) {
+			t.Errorf("total = %d, se esperaba %d", page.Total, len(want))
+		}
+		got = append(got, names(page.Users)...)
+
+		if page.NextCursor == "" {
+			break
+		}
+		if params.Cursor, err = models.DecodeCursor(page.NextCursor); err != nil {
+			t.Fatalf("DecodeCursor: %v", err)
+		}
+	}
+
+	// Orden esperado: edad descendente y, a igual edad, id descendente
+	all, err := repo.List(ctx, listParams(t, "-age"))
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(names(all.Users)) {
+		t.Errorf("páginas = %v, se esperaba %v", got, names(all.Users))
+	}
+	if len(got) != len(want) {
+		t.Errorf("se recorrieron %d usuarios, se esperaban %d", len(got), len(want))
+	}
+	for i := 1; i < len(all.Users); i++ {
+		prev, cur := all.Users[i-1], all.Users[i]
+		if prev.Age < cur.Age || (prev.Age == cur.Age && prev.ID < cur.ID) {
+			t.Errorf("orden incorrecto: %s (%d, %d) antes que %s (%d, %d)", prev.Name, prev.Age, prev.ID, cur.Name, cur.Age, cur.ID)
+		}
+	}
+}
+
+func testListIncludeDeleted(t *testing.T, repo repositories.UserRepository) {
+	ctx := context.Background()
+	mustCreate(t, repo, "Ana", "ana@example.com", 30)
+	deleted := mustCreate(t, repo, "Luis", "luis@example.com", 40)
+
+	if err := repo.Delete(ctx, deleted.ID, 0); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	page, err := repo.List(ctx, listParams(t, "name"))
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if got := names(page.Users); fmt.Sprint(got) != "[Ana]" {
+		t.Errorf("List = %v, se esperaba [Ana]", got)
+	}
+
+	params := listParams(t, "name")
+	params.IncludeDeleted = true
+	page, err = repo.List(ctx, params)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if got := names(page.Users); fmt.Sprint(got) != "[Ana Luis]" {
+		t.Errorf("List con eliminados = %v, se esperaba [Ana Luis]", got)
+	}
+	if page.Users[1].DeletedAt == nil {
+		t.Error("el usuario eliminado no tiene deleted_at")
+	}
+}
+
+func testGetByIDs(t *testing.T, repo repositories.UserRepository) {
+	ctx := context.Background()
+	ana := mustCreate(t, repo, "Ana", "ana@example.com", 30)
+	luis := mustCreate(t, repo, "Luis", "luis@example.com", 40)
+	deleted := mustCreate(t, repo, "Eva", "eva@example.com", 50)
+
+	if err := repo.Delete(ctx, deleted.ID, 0); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	users, err := repo.GetByIDs(ctx, []int{ana.ID, luis.ID, deleted.ID, 999999})
+	if err != nil {
+		t.Fatalf("GetByIDs: %v", err)
+	}
+	if len(users) != 2 || users[ana.ID] == nil || users[luis.ID] == nil {
+		t.Errorf("GetByIDs = %v, se esperaban solo %d y %d", users, ana.ID, luis.ID)
+	}
+}
+
+func testGetByEmails(t *testing.T, repo repositories.UserRepository) {
+	ctx := context.Background()
+	mustCreate(t, repo, "Ana", "Ana@Example.com", 30)
+	deleted := mustCreate(t, repo, "Eva", "eva@example.com", 50)
+
+	if err := repo.Delete(ctx, deleted.ID, 0); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	users, err := repo.GetByEmails(ctx, []string{"ANA@example.com", "eva@example.com", "nadie@example.com"})
+	if err != nil {
+		t.Fatalf("GetByEmails: %v", err)
+	}
+	if len(users) != 1 || users["ana@example.com"] == nil {
+		t.Errorf("GetByEmails = %v, se esperaba solo ana@example.com", users)
+	}
+}
+
+func testCreateManyAtomic(t *testing.T, repo repositories.UserRepository) {
+	ctx := context.Background()
+	mustCreate(t, repo, "Ana", "ana@example.com", 30)
+
+	users := []*models.User{
+		{Name: "Luis", Email: "luis@example.com", Age: 40},
+		{Name: "Otra Ana", Email: "ana@example.com", Age: 25},
+	}
+	created, errs, err := repo.CreateMany(ctx, users, true)
+	if err != nil {
+		t.Fatalf("CreateMany: %v", err)
+	}
+
+	if errs[0] != nil || created[0] != nil {
+		t.Errorf("elemento 0 = %v / %v, se esperaba sin usuario ni error", created[0], errs[0])
+	}
+	assertIs(t, errs[1], models.ErrEmailAlreadyExists)
+
+	_, err = repo.GetByEmail(ctx, "luis@example.com")
+	assertIs(t, err, models.ErrUserNotFound)
+}
+
+func testCreateManyBestEffort(t *testing.T, repo repositories.UserRepository) {
+	ctx := context.Background()
+	mustCreate(t, repo, "Ana", "ana@example.com", 30)
+
+	users := []*models.User{
+		{Name: "Luis", Email: "luis@example.com", Age: 40},
+		{Name: "Otra Ana", Email: "Ana@Example.com", Age: 25},
+		{Name: "Eva", Email: "Eva@Example.com", Age: 50},
+	}
+	created, errs, err := repo.CreateMany(ctx, users, false)
+	if err != nil {
+		t.Fatalf("CreateMany: %v", err)
+	}
+
+	assertIs(t, errs[1], models.ErrEmailAlreadyExists)
+	for _, i := range []int{0, 2} {
+		if errs[i] != nil {
+			t.Errorf("elemento %d: %v", i, errs[i])
+			continue
+		}
+		if created[i] == nil || created[i].ID <= 0 || created[i].Email != users[i].Email || created[i].Version != 1 {
+			t.Errorf("elemento %d = %+v, no coincide con %+v", i, created[i], users[i])
+		}
+	}
+}
+
+func testUpdateMany(t *testing.T, repo repositories.UserRepository) {
+	ctx := context.Background()
+	ana := mustCreate(t, repo, "Ana", "ana@example.com", 30)
+	luis := mustCreate(t, repo, "Luis", "luis@example.com", 40)
+
+	ana.Age = 31
+	luis.Age = 41
+	luis.Version++ // versión desactualizada
+
+	// Atómico: el conflicto de luis impide actualizar a ana
+	_, errs, err := repo.UpdateMany(ctx, []*models.User{ana, luis}, true)
+	if err != nil {
+		t.Fatalf("UpdateMany: %v", err)
+	}
+	assertIs(t, errs[1], models.ErrPreconditionFailed)
+	if current, _ := repo.GetByID(ctx, ana.ID); current == nil || current.Age != 30 {
+		t.Errorf("en modo atómico no debía actualizarse a Ana: %+v", current)
+	}
+
+	// Best effort: ana se actualiza y luis sigue fallando
+	updated, errs, err := repo.UpdateMany(ctx, []*models.User{ana, luis}, false)
+	if err != nil {
+		t.Fatalf("UpdateMany: %v", err)
+	}
+	if errs[0] != nil || updated[0] == nil || updated[0].Age != 31 || updated[0].Version != ana.Version+1 {
+		t.Errorf("elemento 0 = %+v / %v, se esperaba edad 31 y versión %d", updated[0], errs[0], ana.Version+1)
+	}
+	assertIs(t, errs[1], models.ErrPreconditionFailed)
+}
+
+func testDeleteMany(t *testing.T, repo repositories.UserRepository) {
+	ctx := context.Background()
+	ana := mustCreate(t, repo, "Ana", "ana@example.com", 30)
+	luis := mustCreate(t, repo, "Luis", "luis@example.com", 40)
+
+	// Atómico: el id inexistente impide eliminar a ana
+	errs, err := repo.DeleteMany(ctx, []int{ana.ID, 999999}, true)
+	if err != nil {
+		t.Fatalf("DeleteMany: %v", err)
+	}
+	assertIs(t, errs[1], models.ErrUserNotFound)
+	if _, err := repo.GetByID(ctx, ana.ID); err != nil {
+		t.Errorf("en modo atómico no debía eliminarse a Ana: %v", err)
+	}
+
+	errs, err = repo.DeleteMany(ctx, []int{ana.ID, 999999, luis.ID}, false)
+	if err != nil {
+		t.Fatalf("DeleteMany: %v", err)
+	}
+	if errs[0] != nil || errs[2] != nil {
+		t.Errorf("errores = %v, solo se esperaba error en el elemento 1", errs)
+	}
+	assertIs(t, errs[1], models.ErrUserNotFound)
+
+	users, err := repo.GetByIDs(ctx, []int{ana.ID, luis.ID})
+	if err != nil {
+		t.Fatalf("GetByIDs: %v", err)
+	}
+	if len(users) != 0 {
+		t.Errorf("quedaron %d usuarios activos, se esperaban 0", len(users))
+	}
+}
+
+func testExport(t *testing.T, repo repositories.UserRepository) {
+	for i := range 5 {
+		mustCreate(t, repo, fmt.Sprintf("Usuario %d", i), fmt.Sprintf("usuario%d@example.com", i), 20+i)
+	}
+
+	var got []int
+	err := repo.Export(context.Background(), listParams(t, "age", "age>21"), func(user *models.User) error {
+		got = append(got, user.Age)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if fmt.Sprint(got) != "[22 23 24]" {
+		t.Errorf("Export = %v, se esperaba [22 23 24]", got)
+	}
+
+	// Un error de fn detiene la exportación y se propaga
+	stop := errors.New("detener")
+	err = repo.Export(context.Background(), listParams(t, "age"), func(user *models.User) error {
+		return stop
+	})
+	assertIs(t, err, stop)
+}
+
+func mustCreate(t *testing.T, repo repositories.UserRepository, name, email string, age int) *models.User {
+	t.Helper()
+
+	user, err := repo.Create(context.Background(), &models.User{Name: name, Email: email, Age: age})
+	if err != nil {
+		t.Fatalf("Create(%s): %v", email, err)
+	}
+	return user
+}
+
+// listParams arma parámetros de listado válidos con el orden y los filtros indicados.
+func listParams(t *testing.T, sort string, filters ...string) *models.UserListParams {
+	t.Helper()
+
+	params := &models.UserListParams{Limit: models.MaxPageLimit}
+
+	fields, err := models.ParseSort(sort)
+	if err != nil {
+		t.Fatalf("ParseSort(%q): %v", sort, err)
+	}
+	params.Sort = fields
+
+	for _, expr := range filters {
+		filter, err := models.ParseFilter(expr)
+		if err != nil {
+			t.Fatalf("ParseFilter(%q): %v", expr, err)
+		}
+		params.Filters = append(params.Filters, filter)
+	}
+
+	if err := params.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	return params
+}
+
+func names(users []*models.User) []string {
+	result := make([]string, len(users))
+	for i, user := range users {
+		result[i] = user.Name
+	}
+	return result
+}
+
+func assertIs(t *testing.T, err, target error) {
+	t.Helper()
+
+	if !errors.Is(err, target) {
+		t.Errorf("error = %v, se esperaba %v", err, target)
+	}
+}
+
+func testPasswordHash(t *testing.T, repo repositories.UserRepository) {
+	ctx := context.Background()
+	user := mustCreate(t, repo, "Ana", "ana@example.com", 30)
+
+	hash, err := repo.GetPasswordHash(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetPasswordHash: %v", err)
+	}
+	if hash != "" {
+		t.Errorf("hash = %q, se esperaba vacío para un usuario sin contraseña", hash)
+	}
+
+	if err := repo.SetPasswordHash(ctx, user.ID, "$argon2id$v=19$m=65536,t=3,p=2$c2FsdA$aGFzaA"); err != nil {
+		t.Fatalf("SetPasswordHash: %v", err)
+	}
+	hash, err = repo.GetPasswordHash(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetPasswordHash: %v", err)
+	}
+	if hash != "$argon2id$v=19$m=65536,t=3,p=2$c2FsdA$aGFzaA" {
+		t.Errorf("hash = %q, no coincide con el guardado", hash)
+	}
+
+	// El hash no se entrega con el resto de los datos ni cambia la versión
+	found, err := repo.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if found.PasswordHash != "" {
+		t.Errorf("GetByID devolvió el hash de la contraseña")
+	}
+	if found.Version != user.Version {
+		t.Errorf("version = %d, se esperaba %d", found.Version, user.Version)
+	}
+
+	assertIs(t, repo.SetPasswordHash(ctx, 999999, "x"), models.ErrUserNotFound)
+	_, err = repo.GetPasswordHash(ctx, 999999)
+	assertIs(t, err, models.ErrUserNotFound)
+}
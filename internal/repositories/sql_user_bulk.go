@@ -0,0 +1,362 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"pt-brm/internal/database"
+	"pt-brm/internal/models"
+	"strings"
+)
+
+// Cantidad de filas por sentencia en inserciones y consultas IN masivas.
+const bulkBatchSize = 500
+
+// errRollback revierte la transacción de una operación masiva atómica en la
+// que falló algún elemento; no se propaga a quien llama.
+var errRollback = errors.New("operación masiva revertida")
+
+// CreateMany inserta los usuarios con INSERTs de varias filas dentro de una
+// transacción. Devuelve, alineados con la entrada, los usuarios creados y el
+// error de cada uno. Si atomic es true y algún usuario falla no se crea ninguno.
+// El error final solo se usa para fallas que no son de un elemento (conexión, etc.).
+func (r *sqlUserRepository) CreateMany(ctx context.Context, users []*models.User, atomic bool) ([]*models.User, []error, error) {
+	ctx, cancel := r.db.WithTimeout(ctx, database.OpBulk)
+	defer cancel()
+
+	var errs []error
+	err := r.db.WithinTx(ctx, func(ctx context.Context) error {
+		tx := r.db.Executor(ctx)
+		errs = make([]error, len(users))
+		failed := false
+
+		for start := 0; start < len(users); start += bulkBatchSize {
+			end := min(start+bulkBatchSize, len(users))
+
+			err := r.attempt(ctx, func(ctx context.Context) error {
+				return r.insertUsers(ctx, tx, users[start:end])
+			})
+			if err == nil {
+				continue
+			}
+			if r.dialect.translateError(err) == err {
+				return fmt.Errorf("no se pudo crear los usuarios: %w", err)
+			}
+
+			// Solo se revierte la sentencia fallida, así que se reintenta fila
+			// por fila dentro de la misma transacción para saber cuáles fallan.
+			for i := start; i < end; i++ {
+				err := r.attempt(ctx, func(ctx context.Context) error {
+					return r.insertUsers(ctx, tx, users[i:i+1])
+				})
+				if err != nil {
+					translated := r.dialect.translateError(err)
+					if translated == err {
+						return fmt.Errorf("no se pudo crear el usuario: %w", err)
+					}
+					errs[i] = translated
+					failed = true
+				}
+			}
+		}
+
+		if atomic && failed {
+			return errRollback
+		}
+		return nil
+	})
+	if errors.Is(err, errRollback) {
+		return make([]*models.User, len(users)), errs, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Recuperar los usuarios creados por email, que es único entre los activos
+	var emails []string
+	for i, user := range users {
+		if errs[i] == nil {
+			emails = append(emails, user.Email)
+		}
+	}
+
+	byEmail, err := r.getByEmails(ctx, emails)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	created := make([]*models.User, len(users))
+	for i, user := range users {
+		if errs[i] == nil {
+			created[i] = byEmail[strings.ToLower(user.Email)]
+		}
+	}
+
+	return created, errs, nil
+}
+
+// UpdateMany actualiza cada usuario verificando su versión, dentro de una
+// transacción. Devuelve los usuarios actualizados y el error de cada uno,
+// alineados con la entrada.
+func (r *sqlUserRepository) UpdateMany(ctx context.Context, users []*models.User, atomic bool) ([]*models.User, []error, error) {
+	ctx, cancel := r.db.WithTimeout(ctx, database.OpBulk)
+	defer cancel()
+
+	query := `
+		UPDATE users
+		SET name = ?, email = ?, age = ?, version = version + 1, updated_at = ` + r.dialect.now + `
+		WHERE id = ? AND version = ? AND deleted_at IS NULL
+	`
+
+	var errs []error
+	err := r.db.WithinTx(ctx, func(ctx context.Context) error {
+		tx := r.db.Executor(ctx)
+		errs = make([]error, len(users))
+		failed := false
+
+		for i, user := range users {
+			var result sql.Result
+			err := r.attempt(ctx, func(ctx context.Context) (err error) {
+				result, err = tx.ExecContext(ctx, query, user.Name, user.Email, user.Age, user.ID, user.Version)
+				return err
+			})
+			if err != nil {
+				translated := r.dialect.translateError(err)
+				if translated == err {
+					return fmt.Errorf("no se pudo actualizar el usuario: %w", err)
+				}
+				errs[i], failed = translated, true
+				continue
+			}
+
+			rowsAffected, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("no se pudieron obtener las filas afectadas: %w", err)
+			}
+			if rowsAffected == 0 {
+				errs[i], failed = missingOrStale(ctx, tx, user.ID), true
+			}
+		}
+
+		if atomic && failed {
+			return errRollback
+		}
+		return nil
+	})
+	if errors.Is(err, errRollback) {
+		return make([]*models.User, len(users)), errs, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var ids []int
+	for i, user := range users {
+		if errs[i] == nil {
+			ids = append(ids, user.ID)
+		}
+	}
+
+	byID, err := r.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	updated := make([]*models.User, len(users))
+	for i, user := range users {
+		if errs[i] == nil {
+			updated[i] = byID[user.ID]
+		}
+	}
+
+	return updated, errs, nil
+}
+
+// DeleteMany marca como eliminados los usuarios indicados dentro de una
+// transacción. Devuelve el error de cada id, alineado con la entrada.
+func (r *sqlUserRepository) DeleteMany(ctx context.Context, ids []int, atomic bool) ([]error, error) {
+	ctx, cancel := r.db.WithTimeout(ctx, database.OpBulk)
+	defer cancel()
+
+	var errs []error
+	err := r.db.WithinTx(ctx, func(ctx context.Context) error {
+		tx := r.db.Executor(ctx)
+		errs = make([]error, len(ids))
+		failed := false
+
+		for start := 0; start < len(ids); start += bulkBatchSize {
+			end := min(start+bulkBatchSize, len(ids))
+			batch := ids[start:end]
+
+			// Bloquear las filas activas del lote para saber cuáles existen
+			existing := make(map[int]bool, len(batch))
+			rows, err := tx.QueryContext(ctx,
+				"SELECT id FROM users WHERE deleted_at IS NULL AND id IN ("+placeholders(len(batch))+")"+r.dialect.forUpdate,
+				intArgs(batch)...,
+			)
+			if err != nil {
+				return fmt.Errorf("no se pudo consultar los usuarios: %w", err)
+			}
+			for rows.Next() {
+				var id int
+				if err := rows.Scan(&id); err != nil {
+					rows.Close()
+					return fmt.Errorf("no se pudo escanear el usuario: %w", err)
+				}
+				existing[id] = true
+			}
+			rows.Close()
+			if err := rows.Err(); err != nil {
+				return fmt.Errorf("error al iterar filas: %w", err)
+			}
+
+			var found []int
+			for i, id := range batch {
+				if !existing[id] {
+					errs[start+i], failed = models.ErrUserNotFound, true
+					continue
+				}
+				found = append(found, id)
+			}
+
+			if len(found) == 0 {
+				continue
+			}
+
+			_, err = tx.ExecContext(ctx,
+				"UPDATE users SET deleted_at = "+r.dialect.now+", version = version + 1 WHERE id IN ("+placeholders(len(found))+")",
+				intArgs(found)...,
+			)
+			if err != nil {
+				return fmt.Errorf("no se pudo eliminar los usuarios: %w", err)
+			}
+		}
+
+		if atomic && failed {
+			return errRollback
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errRollback) {
+		return nil, err
+	}
+
+	return errs, nil
+}
+
+// attempt ejecuta fn de modo que, si falla, la transacción en curso siga
+// siendo usable. En los motores que la abortan ante cualquier error
+// (Postgres) fn se ejecuta en un savepoint.
+func (r *sqlUserRepository) attempt(ctx context.Context, fn func(ctx context.Context) error) error {
+	if r.dialect.abortsTxOnError {
+		return r.db.WithinTx(ctx, fn)
+	}
+	return fn(ctx)
+}
+
+// GetByIDs devuelve los usuarios activos con los ids indicados, indexados por id.
+func (r *sqlUserRepository) GetByIDs(ctx context.Context, ids []int) (map[int]*models.User, error) {
+	ctx, cancel := r.db.WithTimeout(ctx, database.OpRead)
+	defer cancel()
+
+	users := make(map[int]*models.User, len(ids))
+
+	for start := 0; start < len(ids); start += bulkBatchSize {
+		end := min(start+bulkBatchSize, len(ids))
+		query := "SELECT " + userColumns + " FROM users WHERE deleted_at IS NULL AND id IN (" + placeholders(end-start) + ")"
+
+		err := r.queryUsers(ctx, query, intArgs(ids[start:end]), func(user *models.User) {
+			users[user.ID] = user
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return users, nil
+}
+
+// GetByEmails devuelve los usuarios activos con los emails indicados, indexados
+// por email en minúsculas.
+func (r *sqlUserRepository) GetByEmails(ctx context.Context, emails []string) (map[string]*models.User, error) {
+	ctx, cancel := r.db.WithTimeout(ctx, database.OpRead)
+	defer cancel()
+
+	return r.getByEmails(ctx, emails)
+}
+
+// getByEmails es GetByEmails sin plazo propio, para usarlo dentro de CreateMany.
+func (r *sqlUserRepository) getByEmails(ctx context.Context, emails []string) (map[string]*models.User, error) {
+	users := make(map[string]*models.User, len(emails))
+
+	for start := 0; start < len(emails); start += bulkBatchSize {
+		end := min(start+bulkBatchSize, len(emails))
+		args := make([]any, 0, end-start)
+		marks := make([]string, 0, end-start)
+		for _, email := range emails[start:end] {
+			args = append(args, email)
+			marks = append(marks, r.dialect.fold("?"))
+		}
+		query := "SELECT " + userColumns + " FROM users WHERE deleted_at IS NULL AND " +
+			r.dialect.fold("email") + " IN (" + strings.Join(marks, ", ") + ")"
+
+		err := r.queryUsers(ctx, query, args, func(user *models.User) {
+			users[strings.ToLower(user.Email)] = user
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return users, nil
+}
+
+func (r *sqlUserRepository) queryUsers(ctx context.Context, query string, args []any, fn func(user *models.User)) error {
+	rows, err := r.db.Executor(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("no se pudo consultar los usuarios: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return fmt.Errorf("no se pudo escanear el usuario: %w", err)
+		}
+		fn(user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error al iterar filas: %w", err)
+	}
+
+	return nil
+}
+
+// insertUsers inserta todos los usuarios en una única sentencia de varias filas.
+func (r *sqlUserRepository) insertUsers(ctx context.Context, q database.Executor, users []*models.User) error {
+	values := make([]string, len(users))
+	args := make([]any, 0, len(users)*3)
+	for i, user := range users {
+		values[i] = "(?, ?, ?, " + r.dialect.now + ", " + r.dialect.now + ")"
+		args = append(args, user.Name, user.Email, user.Age)
+	}
+
+	query := "INSERT INTO users (name, email, age, created_at, updated_at) VALUES " + strings.Join(values, ", ")
+	_, err := q.ExecContext(ctx, query, args...)
+	return err
+}
+
+// placeholders devuelve "?, ?, ..." con n marcadores.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func intArgs(values []int) []any {
+	args := make([]any, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
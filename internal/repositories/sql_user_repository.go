@@ -0,0 +1,498 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"pt-brm/internal/database"
+	"pt-brm/internal/models"
+	"time"
+)
+
+// Columnas en el orden que espera scanUser.
+const userColumns = "id, name, email, age, version, created_at, updated_at, deleted_at"
+
+// sqlUserRepository implementa UserRepository sobre database/sql. Las
+// diferencias entre motores quedan en dialect; cada backend lo embebe.
+type sqlUserRepository struct {
+	db      *database.DB
+	dialect sqlDialect
+}
+
+// sqlDialect reúne lo que cambia entre los motores SQL soportados.
+type sqlDialect struct {
+	// now es la expresión SQL del instante actual.
+	now string
+	// like es el operador de búsqueda por patrón (sin distinguir mayúsculas,
+	// con "\" como carácter de escape).
+	like string
+	// forUpdate bloquea las filas leídas dentro de una transacción; vacío si
+	// el motor no lo soporta.
+	forUpdate string
+	// returning indica que el id generado se obtiene con INSERT ... RETURNING
+	// en lugar de LastInsertId.
+	returning bool
+	// abortsTxOnError indica que cualquier error invalida la transacción en
+	// curso, de modo que las sentencias que pueden fallar van en un savepoint.
+	abortsTxOnError bool
+	// translateError convierte las violaciones de restricciones en errores del
+	// dominio y devuelve cualquier otro error sin cambios.
+	translateError func(err error) error
+	// lower es la función SQL que se aplica a ambos lados de las comparaciones
+	// de texto para no distinguir mayúsculas; vacío si la intercalación de las
+	// columnas ya no las distingue (MySQL, SQLite).
+	lower string
+}
+
+// fold envuelve expr con la función lower del dialecto, si la tiene.
+func (d sqlDialect) fold(expr string) string {
+	if d.lower == "" {
+		return expr
+	}
+	return d.lower + "(" + expr + ")"
+}
+
+func (r *sqlUserRepository) Create(ctx context.Context, user *models.User) (*models.User, error) {
+	ctx, cancel := r.db.WithTimeout(ctx, database.OpWrite)
+	defer cancel()
+
+	query := `
+		INSERT INTO users (name, email, age, created_at, updated_at) 
+		VALUES (?, ?, ?, ` + r.dialect.now + `, ` + r.dialect.now + `)`
+
+	// El INSERT y la relectura van en la misma transacción
+	var created *models.User
+	err := r.db.WithinTx(ctx, func(ctx context.Context) error {
+		id, err := r.insertUser(ctx, query, user)
+		if err != nil {
+			// Traducir violaciones de restricciones (email duplicado, datos muy largos...)
+			if translated := r.dialect.translateError(err); translated != err {
+				return translated
+			}
+			return fmt.Errorf("no se pudo crear el usuario: %w", err)
+		}
+
+		// Retornar el usuario creado
+		created, err = r.GetByID(ctx, int(id))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// insertUser ejecuta el INSERT y devuelve el id generado.
+func (r *sqlUserRepository) insertUser(ctx context.Context, query string, user *models.User) (int64, error) {
+	var id int64
+	if r.dialect.returning {
+		err := r.db.Executor(ctx).QueryRowContext(ctx, query+" RETURNING id", user.Name, user.Email, user.Age).Scan(&id)
+		return id, err
+	}
+
+	result, err := r.db.Executor(ctx).ExecContext(ctx, query, user.Name, user.Email, user.Age)
+	if err != nil {
+		return 0, err
+	}
+
+	// Obtener el ID generado
+	id, err = result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("no se pudo obtener el id del registro insertado: %w", err)
+	}
+	return id, nil
+}
+
+func (r *sqlUserRepository) GetAll(ctx context.Context) ([]*models.User, error) {
+	ctx, cancel := r.db.WithTimeout(ctx, database.OpRead)
+	defer cancel()
+
+	query := `
+		SELECT ` + userColumns + `
+		FROM users 
+		WHERE deleted_at IS NULL
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Executor(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo consultar los usuarios: %w", err)
+	}
+	// Asegurarse de cerrar las filas al final
+	defer rows.Close()
+
+	var users []*models.User
+	// Iterar sobre las filas obtenidas
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo escanear el usuario: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar filas: %w", err)
+	}
+
+	return users, nil
+}
+
+func (r *sqlUserRepository) List(ctx context.Context, params *models.UserListParams) (*models.UserPage, error) {
+	ctx, cancel := r.db.WithTimeout(ctx, database.OpRead)
+	defer cancel()
+
+	page := &models.UserPage{
+		Limit:  params.Limit,
+		Offset: params.Offset,
+	}
+
+	conditions, args := listConditions(params, r.dialect)
+
+	// El total respeta los filtros pero no el cursor
+	countQuery := "SELECT COUNT(*) FROM users " + whereClause(conditions)
+	if err := r.db.Executor(ctx).QueryRowContext(ctx, countQuery, args...).Scan(&page.Total); err != nil {
+		return nil, fmt.Errorf("no se pudo contar los usuarios: %w", err)
+	}
+
+	order := params.OrderBy()
+
+	// Paginación keyset: continuar justo después del último registro entregado
+	if params.Cursor != nil {
+		values, err := params.CursorValues()
+		if err != nil {
+			return nil, err
+		}
+		keyset, keysetArgs := buildKeysetCondition(order, values)
+		conditions = append(conditions, keyset)
+		args = append(args, keysetArgs...)
+	}
+
+	query := `
+		SELECT ` + userColumns + `
+		FROM users 
+	` + whereClause(conditions) + buildOrderClause(order) + " LIMIT ? OFFSET ?"
+
+	// Se pide un registro extra para saber si existe una página siguiente
+	args = append(args, params.Limit+1, params.Offset)
+
+	rows, err := r.db.Executor(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo consultar los usuarios: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]*models.User, 0, params.Limit)
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo escanear el usuario: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar filas: %w", err)
+	}
+
+	if len(users) > params.Limit {
+		users = users[:params.Limit]
+		last := users[len(users)-1]
+		page.NextCursor = models.NewCursor(order, last).Encode()
+	}
+	page.Users = users
+
+	return page, nil
+}
+
+// Export recorre todos los usuarios que cumplen los filtros y el orden de
+// params (sin paginar) llamando a fn por cada fila, sin cargarlos en memoria.
+func (r *sqlUserRepository) Export(ctx context.Context, params *models.UserListParams, fn func(user *models.User) error) error {
+	ctx, cancel := r.db.WithTimeout(ctx, database.OpExport)
+	defer cancel()
+
+	conditions, args := listConditions(params, r.dialect)
+	query := "SELECT " + userColumns + " FROM users " + whereClause(conditions) + buildOrderClause(params.OrderBy())
+
+	rows, err := r.db.Executor(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("no se pudo consultar los usuarios: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return fmt.Errorf("no se pudo escanear el usuario: %w", err)
+		}
+		if err := fn(user); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error al iterar filas: %w", err)
+	}
+
+	return nil
+}
+
+func (r *sqlUserRepository) GetByID(ctx context.Context, id int) (*models.User, error) {
+	ctx, cancel := r.db.WithTimeout(ctx, database.OpRead)
+	defer cancel()
+
+	query := `
+		SELECT ` + userColumns + `
+		FROM users 
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	// Ejecutar la consulta y escanear el resultado
+	user, err := scanUser(r.db.Executor(ctx).QueryRowContext(ctx, query, id))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("no se pudo obtener al usuario: %w", err)
+	}
+
+	return user, nil
+}
+
+// Update guarda los cambios solo si la versión almacenada sigue siendo
+// user.Version (concurrencia optimista) e incrementa la versión.
+func (r *sqlUserRepository) Update(ctx context.Context, id int, user *models.User) (*models.User, error) {
+	ctx, cancel := r.db.WithTimeout(ctx, database.OpWrite)
+	defer cancel()
+
+	query := `
+		UPDATE users 
+		SET name = ?, email = ?, age = ?, version = version + 1, updated_at = ` + r.dialect.now + ` 
+		WHERE id = ? AND version = ? AND deleted_at IS NULL
+	`
+
+	var updated *models.User
+	err := r.db.WithinTx(ctx, func(ctx context.Context) error {
+		result, err := r.db.Executor(ctx).ExecContext(ctx, query, user.Name, user.Email, user.Age, id, user.Version)
+		if err != nil {
+			// Traducir violaciones de restricciones (email duplicado, datos muy largos...)
+			if translated := r.dialect.translateError(err); translated != err {
+				return translated
+			}
+			return fmt.Errorf("no se pudo actualizar el usuario: %w", err)
+		}
+
+		// Verificar si se actualizó alguna fila
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("no se pudieron obtener las filas afectadas: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return missingOrStale(ctx, r.db.Executor(ctx), id)
+		}
+
+		// Retornar el usuario actualizado
+		updated, err = r.GetByID(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// Delete marca el usuario como eliminado (soft delete); si version es distinto
+// de 0 solo lo elimina cuando la versión almacenada coincide.
+func (r *sqlUserRepository) Delete(ctx context.Context, id int, version int) error {
+	ctx, cancel := r.db.WithTimeout(ctx, database.OpWrite)
+	defer cancel()
+
+	query := `
+		UPDATE users 
+		SET deleted_at = ` + r.dialect.now + `, version = version + 1 
+		WHERE id = ? AND deleted_at IS NULL AND (? = 0 OR version = ?)
+	`
+
+	result, err := r.db.Executor(ctx).ExecContext(ctx, query, id, version, version)
+	if err != nil {
+		return fmt.Errorf("no se pudo eliminar el usuario: %w", err)
+	}
+
+	// Verificar si se eliminó alguna fila
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("no se pudieron obtener las filas afectadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return missingOrStale(ctx, r.db.Executor(ctx), id)
+	}
+
+	return nil
+}
+
+func (r *sqlUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	ctx, cancel := r.db.WithTimeout(ctx, database.OpRead)
+	defer cancel()
+
+	query := `
+		SELECT ` + userColumns + `
+		FROM users 
+		WHERE ` + r.dialect.fold("email") + ` = ` + r.dialect.fold("?") + ` AND deleted_at IS NULL
+	`
+
+	// Ejecutar la consulta y escanear el resultado
+	user, err := scanUser(r.db.Executor(ctx).QueryRowContext(ctx, query, email))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("no se pudo obtener el usuario: %w", err)
+	}
+
+	return user, nil
+}
+
+// Restore quita la marca de eliminado de un usuario. Falla con conflicto si
+// su email fue tomado por otro usuario mientras estaba eliminado.
+func (r *sqlUserRepository) Restore(ctx context.Context, id int) (*models.User, error) {
+	ctx, cancel := r.db.WithTimeout(ctx, database.OpWrite)
+	defer cancel()
+
+	query := `
+		UPDATE users 
+		SET deleted_at = NULL, version = version + 1, updated_at = ` + r.dialect.now + ` 
+		WHERE id = ? AND deleted_at IS NOT NULL
+	`
+
+	var restored *models.User
+	err := r.db.WithinTx(ctx, func(ctx context.Context) error {
+		result, err := r.db.Executor(ctx).ExecContext(ctx, query, id)
+		if err != nil {
+			// Traducir violaciones de restricciones (email duplicado, datos muy largos...)
+			if translated := r.dialect.translateError(err); translated != err {
+				return translated
+			}
+			return fmt.Errorf("no se pudo restaurar el usuario: %w", err)
+		}
+
+		// Verificar si se restauró alguna fila
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("no se pudieron obtener las filas afectadas: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			// O no existe, o no estaba eliminado
+			if _, err := r.GetByID(ctx, id); err != nil {
+				return err
+			}
+			return models.ErrUserNotDeleted
+		}
+
+		// Retornar el usuario restaurado
+		restored, err = r.GetByID(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return restored, nil
+}
+
+// PurgeDeleted elimina definitivamente los usuarios marcados como eliminados antes de la fecha indicada.
+func (r *sqlUserRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := r.db.WithTimeout(ctx, database.OpBulk)
+	defer cancel()
+
+	query := "DELETE FROM users WHERE deleted_at IS NOT NULL AND deleted_at < ?"
+
+	result, err := r.db.Executor(ctx).ExecContext(ctx, query, sqlValue(before))
+	if err != nil {
+		return 0, fmt.Errorf("no se pudo purgar los usuarios eliminados: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// GetPasswordHash devuelve el hash de la contraseña del usuario; vacío si no tiene.
+func (r *sqlUserRepository) GetPasswordHash(ctx context.Context, id int) (string, error) {
+	ctx, cancel := r.db.WithTimeout(ctx, database.OpRead)
+	defer cancel()
+
+	query := "SELECT password_hash FROM users WHERE id = ? AND deleted_at IS NULL"
+
+	var hash sql.NullString
+	if err := r.db.Executor(ctx).QueryRowContext(ctx, query, id).Scan(&hash); err != nil {
+		if err == sql.ErrNoRows {
+			return "", models.ErrUserNotFound
+		}
+		return "", fmt.Errorf("no se pudo obtener la contraseña del usuario: %w", err)
+	}
+
+	return hash.String, nil
+}
+
+// SetPasswordHash reemplaza el hash de la contraseña. No cambia la versión ni
+// updated_at: la contraseña no forma parte de la representación del usuario.
+func (r *sqlUserRepository) SetPasswordHash(ctx context.Context, id int, hash string) error {
+	ctx, cancel := r.db.WithTimeout(ctx, database.OpWrite)
+	defer cancel()
+
+	// updated_at = updated_at evita el ON UPDATE CURRENT_TIMESTAMP de MySQL
+	query := "UPDATE users SET password_hash = ?, updated_at = updated_at WHERE id = ? AND deleted_at IS NULL"
+
+	result, err := r.db.Executor(ctx).ExecContext(ctx, query, hash, id)
+	if err != nil {
+		return fmt.Errorf("no se pudo guardar la contraseña del usuario: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("no se pudieron obtener las filas afectadas: %w", err)
+	}
+	if rowsAffected == 0 {
+		return models.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// missingOrStale determina por qué una escritura condicionada no afectó filas:
+// el usuario no existe o su versión cambió.
+func missingOrStale(ctx context.Context, q database.Executor, id int) error {
+	var exists bool
+	if err := q.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM users WHERE id = ? AND deleted_at IS NULL)", id).Scan(&exists); err != nil {
+		return fmt.Errorf("no se pudo verificar el usuario: %w", err)
+	}
+	if !exists {
+		return models.ErrUserNotFound
+	}
+	return models.ErrPreconditionFailed
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanUser lee una fila con las columnas de userColumns.
+func scanUser(row rowScanner) (*models.User, error) {
+	user := &models.User{}
+	err := row.Scan(
+		&user.ID,
+		&user.Name,
+		&user.Email,
+		&user.Age,
+		&user.Version,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&user.DeletedAt,
+	)
+	return user, err
+}
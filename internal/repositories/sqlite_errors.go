@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"pt-brm/internal/models"
+	"regexp"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+var (
+	// UNIQUE constraint failed: users.email / NOT NULL constraint failed: users.name
+	sqliteColumnPattern = regexp.MustCompile(`constraint failed: \w+\.(\w+)`)
+	// CHECK constraint failed: name_length
+	sqliteLengthPattern = regexp.MustCompile(`CHECK constraint failed: (\w+)_length`)
+)
+
+// translateSQLiteError convierte los errores de restricciones de SQLite en
+// los mismos errores del dominio que translateMySQLError. Cualquier otro
+// error se devuelve sin cambios.
+func translateSQLiteError(err error) error {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+
+	switch sqliteErr.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE:
+		column := submatch(sqliteColumnPattern, sqliteErr.Error())
+		if column == "email" {
+			return models.ErrEmailAlreadyExists
+		}
+		conflict := models.NewConflictError("duplicate_value", fmt.Sprintf("ya existe un registro con el mismo valor en %s", column))
+		conflict.Field = column
+		return conflict
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		return models.NewValidationError("invalid_reference", "", "el valor hace referencia a un registro inexistente")
+	case sqlite3.SQLITE_CONSTRAINT_CHECK:
+		if column := submatch(sqliteLengthPattern, sqliteErr.Error()); column != "" {
+			return models.NewValidationError("value_too_long", column, fmt.Sprintf("el valor de %s es demasiado largo", column))
+		}
+	case sqlite3.SQLITE_CONSTRAINT_NOTNULL:
+		column := submatch(sqliteColumnPattern, sqliteErr.Error())
+		return models.NewValidationError("field_required", column, fmt.Sprintf("el campo %s es requerido", column))
+	}
+
+	return err
+}
@@ -0,0 +1,27 @@
+package repositories
+
+import "pt-brm/internal/database"
+
+// SQLiteUserRepository guarda los usuarios en SQLite, pensado para desarrollo
+// local y CI sin el MySQL de docker-compose.
+type SQLiteUserRepository struct {
+	*sqlUserRepository
+}
+
+func NewSQLiteUserRepository(db *database.DB) UserRepository {
+	return &SQLiteUserRepository{
+		sqlUserRepository: &sqlUserRepository{
+			db:      db,
+			dialect: sqliteDialect,
+		},
+	}
+}
+
+var sqliteDialect = sqlDialect{
+	// Mismo formato con el que el driver escribe las fechas (_time_format=sqlite)
+	now:  "strftime('%Y-%m-%d %H:%M:%S+00:00', 'now')",
+	like: ` LIKE ? ESCAPE '\'`,
+	// SQLite bloquea la base completa al escribir, no hace falta FOR UPDATE
+	forUpdate:      "",
+	translateError: translateSQLiteError,
+}
@@ -0,0 +1,34 @@
+package repositories_test
+
+import (
+	"context"
+	"pt-brm/internal/config"
+	"pt-brm/internal/database"
+	"pt-brm/internal/repositories"
+	"pt-brm/internal/repositories/repotest"
+	"testing"
+)
+
+func TestSQLiteUserRepository(t *testing.T) {
+	repotest.TestUserRepository(t, func(t *testing.T) repositories.UserRepository {
+		return newSQLiteRepository(t)
+	})
+}
+
+// newSQLiteRepository crea un repositorio sobre una base SQLite en memoria con
+// las migraciones aplicadas; cada llamada parte de una base vacía.
+func newSQLiteRepository(t *testing.T) repositories.UserRepository {
+	t.Helper()
+
+	ctx := context.Background()
+	db, err := database.NewConnection(ctx, config.DatabaseConfig{Driver: config.DriverSQLite, Path: ":memory:"})
+	if err != nil {
+		t.Fatalf("NewConnection: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := db.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	return repositories.NewUserRepository(db)
+}
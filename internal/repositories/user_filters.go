@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"pt-brm/internal/models"
+	"strings"
+	"time"
+)
+
+// Las columnas provienen siempre de la lista blanca de models, los valores
+// viajan como parámetros; nunca se concatena texto recibido del cliente.
+
+// listConditions arma las condiciones de un listado: los filtros del cliente y,
+// salvo que se pidan explícitamente, la exclusión de los usuarios eliminados.
+func listConditions(params *models.UserListParams, dialect sqlDialect) ([]string, []any) {
+	conditions, args := buildFilterConditions(params.Filters, dialect)
+	if !params.IncludeDeleted {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+	return conditions, args
+}
+
+// buildFilterConditions traduce los filtros a condiciones SQL parametrizadas.
+func buildFilterConditions(filters []models.Filter, dialect sqlDialect) ([]string, []any) {
+	conditions := make([]string, 0, len(filters))
+	args := make([]any, 0, len(filters))
+
+	for _, f := range filters {
+		switch f.Operator {
+		case models.OpContains:
+			conditions = append(conditions, f.Column+dialect.like)
+			args = append(args, "%"+escapeLike(f.Value.(string))+"%")
+		case models.OpDomain:
+			conditions = append(conditions, f.Column+dialect.like)
+			args = append(args, "%@"+escapeLike(strings.TrimPrefix(f.Value.(string), "@")))
+		default:
+			column, mark := f.Column, "?"
+			if _, ok := f.Value.(string); ok {
+				column, mark = dialect.fold(column), dialect.fold(mark)
+			}
+			conditions = append(conditions, column+" "+string(f.Operator)+" "+mark)
+			args = append(args, sqlValue(f.Value))
+		}
+	}
+
+	return conditions, args
+}
+
+// buildKeysetCondition genera la condición para continuar después del cursor:
+// (a > ?) OR (a = ? AND b < ?) OR ... según la dirección de cada campo.
+func buildKeysetCondition(order []models.SortField, values []any) (string, []any) {
+	var branches []string
+	var args []any
+
+	for i, f := range order {
+		parts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			parts = append(parts, order[j].Column+" = ?")
+			args = append(args, sqlValue(values[j]))
+		}
+
+		op := " > ?"
+		if f.Desc {
+			op = " < ?"
+		}
+		parts = append(parts, f.Column+op)
+		args = append(args, sqlValue(values[i]))
+
+		branches = append(branches, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	return "(" + strings.Join(branches, " OR ") + ")", args
+}
+
+// buildOrderClause genera el ORDER BY a partir del orden ya validado.
+func buildOrderClause(order []models.SortField) string {
+	parts := make([]string, len(order))
+	for i, f := range order {
+		if f.Desc {
+			parts[i] = f.Column + " DESC"
+		} else {
+			parts[i] = f.Column + " ASC"
+		}
+	}
+	return "ORDER BY " + strings.Join(parts, ", ")
+}
+
+// whereClause une las condiciones con AND, o devuelve vacío si no hay ninguna.
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(conditions, " AND ") + " "
+}
+
+// sqlValue pasa las fechas a UTC: SQLite las compara como texto, así que
+// todas deben tener la misma zona horaria que las almacenadas.
+func sqlValue(value any) any {
+	if t, ok := value.(time.Time); ok {
+		return t.UTC()
+	}
+	return value
+}
+
+// escapeLike escapa los comodines de LIKE para que se busquen literalmente.
+func escapeLike(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(value)
+}
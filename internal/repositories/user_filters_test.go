@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"pt-brm/internal/models"
+)
+
+func TestBuildFilterConditions(t *testing.T) {
+	tests := []struct {
+		filter   string
+		dialect  sqlDialect
+		want     string
+		wantArgs []any
+	}{
+		{filter: "email=Ana@Example.com", dialect: mysqlDialect, want: "email = ?", wantArgs: []any{"Ana@Example.com"}},
+		{filter: "email=Ana@Example.com", dialect: postgresDialect, want: "LOWER(email) = LOWER(?)", wantArgs: []any{"Ana@Example.com"}},
+		{filter: "name!=Luis", dialect: postgresDialect, want: "LOWER(name) != LOWER(?)", wantArgs: []any{"Luis"}},
+		{filter: "name!=Luis", dialect: sqliteDialect, want: "name != ?", wantArgs: []any{"Luis"}},
+		{filter: "age>=18", dialect: postgresDialect, want: "age >= ?", wantArgs: []any{18}},
+		{filter: "name~=a_b", dialect: postgresDialect, want: "name ILIKE ?", wantArgs: []any{`%a\_b%`}},
+		{filter: "email@=example.com", dialect: mysqlDialect, want: "email LIKE ?", wantArgs: []any{"%@example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			filter, err := models.ParseFilter(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			conditions, args := buildFilterConditions([]models.Filter{filter}, tt.dialect)
+			if got := strings.Join(conditions, " AND "); got != tt.want {
+				t.Errorf("condición = %q, se esperaba %q", got, tt.want)
+			}
+			if fmt.Sprint(args) != fmt.Sprint(tt.wantArgs) {
+				t.Errorf("args = %v, se esperaba %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
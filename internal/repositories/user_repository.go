@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"context"
+	"pt-brm/internal/config"
+	"pt-brm/internal/database"
+	"pt-brm/internal/models"
+	"time"
+)
+
+type UserRepository interface {
+	Create(ctx context.Context, user *models.User) (*models.User, error)
+	GetAll(ctx context.Context) ([]*models.User, error)
+	List(ctx context.Context, params *models.UserListParams) (*models.UserPage, error)
+	GetByID(ctx context.Context, id int) (*models.User, error)
+	Update(ctx context.Context, id int, user *models.User) (*models.User, error)
+	Delete(ctx context.Context, id int, version int) error
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	Restore(ctx context.Context, id int) (*models.User, error)
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+	GetByIDs(ctx context.Context, ids []int) (map[int]*models.User, error)
+	GetByEmails(ctx context.Context, emails []string) (map[string]*models.User, error)
+	CreateMany(ctx context.Context, users []*models.User, atomic bool) ([]*models.User, []error, error)
+	UpdateMany(ctx context.Context, users []*models.User, atomic bool) ([]*models.User, []error, error)
+	DeleteMany(ctx context.Context, ids []int, atomic bool) ([]error, error)
+	Export(ctx context.Context, params *models.UserListParams, fn func(user *models.User) error) error
+	GetPasswordHash(ctx context.Context, id int) (string, error)
+	SetPasswordHash(ctx context.Context, id int, hash string) error
+}
+
+// NewUserRepository crea el repositorio de usuarios que corresponde al motor
+// de la conexión.
+func NewUserRepository(db *database.DB) UserRepository {
+	switch db.Driver() {
+	case config.DriverSQLite:
+		return NewSQLiteUserRepository(db)
+	case config.DriverPostgres:
+		return NewPostgresUserRepository(db)
+	default:
+		return NewMySQLUserRepository(db)
+	}
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"pt-brm/internal/handlers"
+
+	"github.com/gorilla/mux"
+)
+
+// SetupAPIKeyRoutes configura las rutas de administración de API keys.
+func SetupAPIKeyRoutes(router *mux.Router, apiKeyHandler *handlers.APIKeyHandler, middlewares ...mux.MiddlewareFunc) {
+	keys := router.PathPrefix("/api-keys").Subrouter()
+	keys.Use(middlewares...)
+
+	keys.HandleFunc("", apiKeyHandler.CreateAPIKey).Methods("POST").Name("api_keys.create")
+	keys.HandleFunc("", apiKeyHandler.ListAPIKeys).Methods("GET").Name("api_keys.list")
+	keys.HandleFunc("/{id}", apiKeyHandler.RevokeAPIKey).Methods("DELETE").Name("api_keys.revoke")
+	keys.HandleFunc("/{id}/rotate", apiKeyHandler.RotateAPIKey).Methods("POST").Name("api_keys.rotate")
+}
@@ -0,0 +1,125 @@
+package routes
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"pt-brm/internal/auth"
+	"pt-brm/internal/models"
+	"pt-brm/internal/services"
+	"pt-brm/pkg/response"
+	"strconv"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/mux"
+)
+
+// apiKeyHeader es el header con el que los servicios envían su API key.
+const apiKeyHeader = "X-API-Key"
+
+// authenticate exige un token Bearer o una API key válidos y guarda sus
+// claims en el contexto de la solicitud (auth.ClaimsFromContext).
+func authenticate(verifier *auth.Verifier, apiKeys services.APIKeyService) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if key := r.Header.Get(apiKeyHeader); key != "" {
+				apiKey, err := apiKeys.Authenticate(r.Context(), key)
+				if err != nil {
+					switch {
+					case errors.Is(err, services.ErrInvalidAPIKey), errors.Is(err, services.ErrAPIKeyExpired):
+						slog.Debug("API key rechazada", "path", r.URL.Path, "error", err)
+						response.Error(w, http.StatusUnauthorized, err.Error())
+					default:
+						response.FromError(w, err)
+					}
+					return
+				}
+
+				claims := auth.NewAPIKeyClaims(apiKey.ID, apiKey.Scopes)
+				next.ServeHTTP(w, r.WithContext(auth.WithClaims(r.Context(), claims)))
+				return
+			}
+
+			token, ok := bearerToken(r)
+			if !ok {
+				w.Header().Set("WWW-Authenticate", `Bearer`)
+				response.Error(w, http.StatusUnauthorized, "se requiere un token de acceso o una API key")
+				return
+			}
+
+			claims, err := verifier.Verify(token)
+			if err != nil {
+				slog.Debug("token rechazado", "path", r.URL.Path, "error", err)
+				message := "token inválido"
+				if errors.Is(err, jwt.ErrTokenExpired) {
+					message = "token expirado"
+				}
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				response.Error(w, http.StatusUnauthorized, message)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithClaims(r.Context(), claims)))
+		})
+	}
+}
+
+// bearerToken extrae el token del header "Authorization: Bearer <token>".
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// requireScope responde 403 si el token o la API key no tiene el permiso.
+func requireScope(scope string) mux.MiddlewareFunc {
+	return requireScopeFor(func(*http.Request) string { return scope })
+}
+
+// requireMethodScope exige readScope en las lecturas (GET, HEAD) y writeScope
+// en el resto de métodos.
+func requireMethodScope(readScope, writeScope string) mux.MiddlewareFunc {
+	return requireScopeFor(func(r *http.Request) string {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			return readScope
+		}
+		return writeScope
+	})
+}
+
+// userAdminScope exige users:admin para purgar usuarios y para incluir los
+// eliminados en los listados y exportaciones (include_deleted).
+func userAdminScope(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil && route.GetName() == "users.purge" {
+		return models.ScopeUsersAdmin
+	}
+	if includeDeleted, _ := strconv.ParseBool(r.URL.Query().Get("include_deleted")); includeDeleted {
+		return models.ScopeUsersAdmin
+	}
+	return ""
+}
+
+// requireScopeFor exige el permiso que scopeFor indique para la solicitud;
+// una cadena vacía significa que no hace falta ninguno.
+func requireScopeFor(scopeFor func(r *http.Request) string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scope := scopeFor(r)
+			if scope == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			claims, ok := auth.ClaimsFromContext(r.Context())
+			if !ok || !claims.HasScope(scope) {
+				w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+scope+`"`)
+				response.Error(w, http.StatusForbidden, "se requiere el permiso "+scope)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
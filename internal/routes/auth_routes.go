@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"pt-brm/internal/handlers"
+
+	"github.com/gorilla/mux"
+)
+
+// SetupAuthRoutes configura el inicio de sesión en public, que no exige
+// credenciales, y el cambio de contraseña en api. El cambio de contraseña no
+// pasa por los permisos de /users: lo autoriza el handler.
+func SetupAuthRoutes(public, api *mux.Router, authHandler *handlers.AuthHandler) {
+	public.HandleFunc("/auth/login", authHandler.Login).Methods("POST").Name("auth.login")
+	api.HandleFunc("/users/{id}/password", authHandler.ChangePassword).Methods("POST").Name("users.password")
+}
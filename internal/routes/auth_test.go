@@ -0,0 +1,154 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pt-brm/internal/auth"
+	"pt-brm/internal/config"
+	"pt-brm/internal/models"
+	"pt-brm/internal/services"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/mux"
+)
+
+const testSecret = "secreto-de-prueba-de-32-caracteres!!"
+
+// fakeAPIKeys acepta solo las claves de keys; el resto de métodos no se usan.
+type fakeAPIKeys struct {
+	services.APIKeyService
+	keys map[string]*models.APIKey
+}
+
+func (f fakeAPIKeys) Authenticate(ctx context.Context, raw string) (*models.APIKey, error) {
+	if key, ok := f.keys[raw]; ok {
+		return key, nil
+	}
+	return nil, services.ErrInvalidAPIKey
+}
+
+func testToken(t *testing.T, scope string, exp time.Time) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":   "1",
+		"iat":   time.Now().Unix(),
+		"exp":   exp.Unix(),
+		"scope": scope,
+	})
+	signed, err := token.SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestAuthenticateAndScopes(t *testing.T) {
+	verifier, err := auth.NewVerifier(config.AuthConfig{HS256Secret: testSecret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiKeys := fakeAPIKeys{keys: map[string]*models.APIKey{
+		"ptb_lectura.s": {ID: 1, Scopes: []string{models.ScopeUsersRead}},
+		"ptb_todo.s":    {ID: 2, Scopes: []string{models.ScopeUsersRead, models.ScopeUsersWrite}},
+	}}
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	users := authenticate(verifier, apiKeys)(requireMethodScope(models.ScopeUsersRead, models.ScopeUsersWrite)(ok))
+	manage := authenticate(verifier, apiKeys)(requireScope(models.ScopeAPIKeysManage)(ok))
+
+	now := time.Now()
+	tests := []struct {
+		name    string
+		handler http.Handler
+		method  string
+		header  string
+		value   string
+		want    int
+	}{
+		{name: "sin credenciales", handler: users, method: "GET", want: http.StatusUnauthorized},
+		{name: "esquema no Bearer", handler: users, method: "GET", header: "Authorization", value: "Basic dXNlcjpwYXNz", want: http.StatusUnauthorized},
+		{name: "token inválido", handler: users, method: "GET", header: "Authorization", value: "Bearer x.y.z", want: http.StatusUnauthorized},
+		{name: "token expirado", handler: users, method: "GET", header: "Authorization", value: "Bearer " + testToken(t, "users:read", now.Add(-time.Hour)), want: http.StatusUnauthorized},
+		{name: "token de lectura en GET", handler: users, method: "GET", header: "Authorization", value: "Bearer " + testToken(t, "users:read", now.Add(time.Hour)), want: http.StatusOK},
+		{name: "token de lectura en HEAD", handler: users, method: "HEAD", header: "Authorization", value: "Bearer " + testToken(t, "users:read", now.Add(time.Hour)), want: http.StatusOK},
+		{name: "token de lectura en POST", handler: users, method: "POST", header: "Authorization", value: "Bearer " + testToken(t, "users:read", now.Add(time.Hour)), want: http.StatusForbidden},
+		{name: "token de escritura en DELETE", handler: users, method: "DELETE", header: "Authorization", value: "Bearer " + testToken(t, "users:read users:write", now.Add(time.Hour)), want: http.StatusOK},
+		{name: "token sin scope", handler: users, method: "GET", header: "Authorization", value: "Bearer " + testToken(t, "", now.Add(time.Hour)), want: http.StatusForbidden},
+		{name: "token de administración", handler: manage, method: "POST", header: "Authorization", value: "Bearer " + testToken(t, "api_keys:manage", now.Add(time.Hour)), want: http.StatusOK},
+		{name: "API key desconocida", handler: users, method: "GET", header: apiKeyHeader, value: "ptb_otra.s", want: http.StatusUnauthorized},
+		{name: "API key de lectura en GET", handler: users, method: "GET", header: apiKeyHeader, value: "ptb_lectura.s", want: http.StatusOK},
+		{name: "API key de lectura en PATCH", handler: users, method: "PATCH", header: apiKeyHeader, value: "ptb_lectura.s", want: http.StatusForbidden},
+		{name: "API key de escritura en PATCH", handler: users, method: "PATCH", header: apiKeyHeader, value: "ptb_todo.s", want: http.StatusOK},
+		{name: "API key en administración", handler: manage, method: "GET", header: apiKeyHeader, value: "ptb_todo.s", want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/api/v1/users", nil)
+			if tt.header != "" {
+				r.Header.Set(tt.header, tt.value)
+			}
+			w := httptest.NewRecorder()
+			tt.handler.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, se esperaba %d (%s)", w.Code, tt.want, w.Body.String())
+			}
+			if w.Code == http.StatusForbidden && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("falta el header WWW-Authenticate en el 403")
+			}
+		})
+	}
+}
+
+func TestUserAdminScope(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	router := mux.NewRouter()
+	users := router.PathPrefix("/users").Subrouter()
+	users.Use(requireMethodScope(models.ScopeUsersRead, models.ScopeUsersWrite), requireScopeFor(userAdminScope))
+	users.HandleFunc("", ok).Methods("GET")
+	users.HandleFunc("/export", ok).Methods("GET").Name("users.export")
+	users.HandleFunc("/purge", ok).Methods("POST").Name("users.purge")
+
+	writer := &auth.Claims{Scope: "users:read users:write"}
+	admin := &auth.Claims{Scope: "users:read users:write users:admin"}
+	apiKey := auth.NewAPIKeyClaims(1, models.APIKeyScopes)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		claims *auth.Claims
+		want   int
+	}{
+		{name: "listado", method: "GET", target: "/users", claims: writer, want: http.StatusOK},
+		{name: "listado sin eliminados", method: "GET", target: "/users?include_deleted=false", claims: writer, want: http.StatusOK},
+		{name: "listado con eliminados", method: "GET", target: "/users?include_deleted=true", claims: writer, want: http.StatusForbidden},
+		{name: "listado con eliminados como 1", method: "GET", target: "/users?include_deleted=1", claims: apiKey, want: http.StatusForbidden},
+		{name: "exportación con eliminados", method: "GET", target: "/users/export?include_deleted=true", claims: writer, want: http.StatusForbidden},
+		{name: "listado con eliminados como administrador", method: "GET", target: "/users?include_deleted=true", claims: admin, want: http.StatusOK},
+		{name: "purga", method: "POST", target: "/users/purge?older_than=720h", claims: writer, want: http.StatusForbidden},
+		{name: "purga con API key", method: "POST", target: "/users/purge?older_than=720h", claims: apiKey, want: http.StatusForbidden},
+		{name: "purga como administrador", method: "POST", target: "/users/purge?older_than=720h", claims: admin, want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.target, nil)
+			r = r.WithContext(auth.WithClaims(r.Context(), tt.claims))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, se esperaba %d", w.Code, tt.want)
+			}
+		})
+	}
+}
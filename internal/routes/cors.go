@@ -0,0 +1,40 @@
+package routes
+
+import (
+	"net/http"
+	"pt-brm/internal/config"
+	"sync/atomic"
+
+	"github.com/rs/cors"
+)
+
+// reloadableCORS aplica CORS con los orígenes vigentes; se reconstruye cuando
+// cambia CORS_ALLOWED_ORIGINS.
+type reloadableCORS struct {
+	next    http.Handler
+	handler atomic.Pointer[http.Handler]
+}
+
+func newReloadableCORS(next http.Handler, origins []string) *reloadableCORS {
+	c := &reloadableCORS{next: next}
+	c.setOrigins(origins)
+	return c
+}
+
+func (c *reloadableCORS) setOrigins(origins []string) {
+	handler := cors.New(cors.Options{
+		AllowedOrigins: origins,
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders: []string{"*"},
+		ExposedHeaders: []string{"ETag"},
+	}).Handler(c.next)
+	c.handler.Store(&handler)
+}
+
+func (c *reloadableCORS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	(*c.handler.Load()).ServeHTTP(w, r)
+}
+
+func (c *reloadableCORS) ConfigChanged(cfg *config.Config) {
+	c.setOrigins(cfg.Server.CORSOrigins)
+}
@@ -0,0 +1,37 @@
+package routes
+
+import (
+	"net/http"
+	"pt-brm/internal/config"
+	"pt-brm/pkg/response"
+	"sync/atomic"
+
+	"github.com/gorilla/mux"
+)
+
+// featureFlags responde 404 en las rutas cuya función está desactivada en
+// FEATURE_FLAGS (por nombre de ruta, por ejemplo users.import=false).
+type featureFlags struct {
+	features atomic.Pointer[config.Features]
+}
+
+func newFeatureFlags(features config.Features) *featureFlags {
+	f := &featureFlags{}
+	f.features.Store(&features)
+	return f
+}
+
+func (f *featureFlags) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route := mux.CurrentRoute(r); route != nil && !f.features.Load().Enabled(route.GetName()) {
+			response.Error(w, http.StatusNotFound, "función deshabilitada")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (f *featureFlags) ConfigChanged(cfg *config.Config) {
+	features := cfg.Features
+	f.features.Store(&features)
+}
@@ -1,6 +1,8 @@
 package routes
 
 import (
+	"encoding/json"
+	"log"
 	"net/http"
 	"pt-brm/internal/database"
 
@@ -11,18 +13,54 @@ import (
 func SetupHealthRoutes(router *mux.Router, db *database.DB) {
 	router.HandleFunc("/health", healthCheck(db)).Methods("GET")
 	router.HandleFunc("/ping", pingHandler()).Methods("GET")
+	router.HandleFunc("/live", liveHandler()).Methods("GET")
+	router.HandleFunc("/ready", readyHandler(db)).Methods("GET")
 }
 
 func healthCheck(db *database.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if err := db.Ping(); err != nil {
+		if err := db.PingContext(r.Context()); err != nil {
 			http.Error(w, "no se pudo conectar con la base de datos", http.StatusServiceUnavailable)
 			return
 		}
 
+		// Estado TLS negociado con la base de datos
+		tlsStatus, err := db.TLSStatus(r.Context())
+		if err != nil {
+			log.Printf("health: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]any{
+			"status":   "healthy",
+			"database": "connected",
+			"tls":      tlsStatus,
+		})
+	}
+}
+
+// liveHandler es la sonda de liveness: el proceso responde, aunque la base de
+// datos aún no esté disponible.
+func liveHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"status": "healthy", "database": "connected"}`))
+		w.Write([]byte(`{"status": "alive"}`))
+	}
+}
+
+// readyHandler es la sonda de readiness: la API puede atender solicitudes.
+func readyHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := db.PingContext(r.Context()); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"status": "not_ready", "database": "unavailable"}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"status": "ready", "database": "connected"}`))
 	}
 }
 
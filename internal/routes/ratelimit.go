@@ -0,0 +1,97 @@
+package routes
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"pt-brm/internal/config"
+	"pt-brm/pkg/response"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateLimiter limita las solicitudes por IP con un token bucket: cada cliente
+// acumula rps tokens por segundo hasta burst y cada solicitud consume uno.
+// Los límites se pueden cambiar en caliente.
+type rateLimiter struct {
+	mu        sync.Mutex
+	rps       float64
+	burst     float64
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// bucketIdleTime es el tiempo tras el cual se descarta el bucket de un cliente inactivo.
+const bucketIdleTime = time.Minute
+
+func newRateLimiter(cfg config.RateLimitConfig) *rateLimiter {
+	l := &rateLimiter{buckets: make(map[string]*tokenBucket)}
+	l.setLimits(cfg)
+	return l
+}
+
+func (l *rateLimiter) setLimits(cfg config.RateLimitConfig) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rps = float64(cfg.RPS)
+	l.burst = float64(cfg.Burst)
+}
+
+// allow indica si el cliente puede hacer una solicitud ahora y, si no, cuánto
+// debe esperar.
+func (l *rateLimiter) allow(client string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rps <= 0 {
+		return true, 0
+	}
+
+	if now.Sub(l.lastSweep) > bucketIdleTime {
+		for key, bucket := range l.buckets {
+			if now.Sub(bucket.last) > bucketIdleTime {
+				delete(l.buckets, key)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	bucket, ok := l.buckets[client]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[client] = bucket
+	}
+	bucket.tokens = min(l.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*l.rps)
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		return false, time.Duration((1 - bucket.tokens) / l.rps * float64(time.Second))
+	}
+	bucket.tokens--
+	return true, 0
+}
+
+func (l *rateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			client = r.RemoteAddr
+		}
+		if ok, wait := l.allow(client, time.Now()); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			response.Error(w, http.StatusTooManyRequests, "demasiadas solicitudes, intente más tarde")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (l *rateLimiter) ConfigChanged(cfg *config.Config) {
+	l.setLimits(cfg.RateLimit)
+}
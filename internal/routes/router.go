@@ -2,28 +2,51 @@ package routes
 
 import (
 	"net/http"
+	"pt-brm/internal/auth"
+	"pt-brm/internal/config"
 	"pt-brm/internal/database"
 	"pt-brm/internal/handlers"
+	"pt-brm/internal/models"
 	"pt-brm/internal/repositories"
 	"pt-brm/internal/services"
 
 	"github.com/gorilla/mux"
-	"github.com/rs/cors"
 )
 
 type Router struct {
-	db *database.DB
+	db       *database.DB
+	watcher  *config.Watcher
+	security Security
 }
 
-func NewRouter(db *database.DB) *Router {
-	return &Router{db: db}
+// Security reúne los componentes de autenticación, que se crean al arrancar
+// porque dependen de archivos de claves y listas que pueden fallar al leerse.
+type Security struct {
+	// Verifier valida los tokens; nil si la API no exige autenticación.
+	Verifier *auth.Verifier
+	// Issuer firma los tokens de login; nil si no hay clave de firma.
+	Issuer *auth.Issuer
+	Hasher *auth.PasswordHasher
+	Policy *auth.PasswordPolicy
+}
+
+// NewRouter crea el router; los componentes que admiten recarga (CORS, límite
+// de solicitudes y feature flags) se suscriben a watcher.
+func NewRouter(db *database.DB, watcher *config.Watcher, security Security) *Router {
+	return &Router{db: db, watcher: watcher, security: security}
 }
 
 func (rt *Router) SetupRoutes() http.Handler {
+	cfg := rt.watcher.Current()
+
 	// Crear dependencias
-	userRepo := repositories.NewMySQLUserRepository(rt.db)
-	userService := services.NewUserService(userRepo)
-	userHandler := handlers.NewUserHandler(userService)
+	userRepo := repositories.NewUserRepository(rt.db)
+	userService := services.NewUserService(userRepo, rt.db)
+	userHandler := handlers.NewUserHandler(userService, cfg.Purge.Retention)
+	apiKeyService := services.NewAPIKeyService(repositories.NewAPIKeyRepository(rt.db))
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	credentialService := services.NewCredentialService(userRepo, rt.security.Hasher, rt.security.Policy)
+	authHandler := handlers.NewAuthHandler(credentialService, rt.security.Issuer)
 
 	// Router principal
 	router := mux.NewRouter()
@@ -31,18 +54,41 @@ func (rt *Router) SetupRoutes() http.Handler {
 	// Rutas de salud
 	SetupHealthRoutes(router, rt.db)
 
+	// Límite de solicitudes por cliente y funciones desactivables
+	limiter := newRateLimiter(cfg.RateLimit)
+	features := newFeatureFlags(cfg.Features)
+
+	// Rutas públicas de API v1 (inicio de sesión); van antes que apiV1, que exige credenciales
+	public := router.PathPrefix("/api/v1").Subrouter()
+	public.Use(limiter.Middleware, features.Middleware)
+
 	// API v1
+	verifier := rt.security.Verifier
 	apiV1 := router.PathPrefix("/api/v1").Subrouter()
+	apiV1.Use(limiter.Middleware)
+	if verifier != nil {
+		apiV1.Use(authenticate(verifier, apiKeyService))
+	}
+	apiV1.Use(features.Middleware)
+	rt.watcher.Subscribe(limiter)
+	rt.watcher.Subscribe(features)
+
+	// Rutas por módulo; con autenticación cada una exige su permiso
+	var userScopes, apiKeyScopes []mux.MiddlewareFunc
+	if verifier != nil {
+		userScopes = append(userScopes, requireMethodScope(models.ScopeUsersRead, models.ScopeUsersWrite), requireScopeFor(userAdminScope))
+		apiKeyScopes = append(apiKeyScopes, requireScope(models.ScopeAPIKeysManage))
+	}
+	SetupAuthRoutes(public, apiV1, authHandler)
+	SetupUserRoutes(apiV1, userHandler, userScopes...)
+	SetupAPIKeyRoutes(apiV1, apiKeyHandler, apiKeyScopes...)
 
-	// Rutas por módulo
-	SetupUserRoutes(apiV1, userHandler)
+	// Tiempos de lectura y escritura por ruta (por ejemplo, importaciones y exportaciones largas)
+	router.Use(routeTimeouts(router, cfg.Server.RouteReadTimeouts, cfg.Server.RouteWriteTimeouts))
 
 	// CORS
-	c := cors.New(cors.Options{
-		AllowedOrigins: []string{"*"},
-		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders: []string{"*"},
-	})
+	c := newReloadableCORS(router, cfg.Server.CORSOrigins)
+	rt.watcher.Subscribe(c)
 
-	return c.Handler(router)
+	return accessLog(c)
 }
@@ -0,0 +1,50 @@
+package routes
+
+import (
+	"net/http"
+	"pt-brm/pkg/response"
+	"sync/atomic"
+)
+
+// StartupGate atiende las solicitudes mientras la API arranca: el proceso ya
+// escucha, pero hasta que la base de datos está disponible y migrada las
+// sondas de readiness responden 503 y el resto de rutas también. SetReady
+// instala el router completo.
+type StartupGate struct {
+	handler atomic.Pointer[http.Handler]
+}
+
+func NewStartupGate() *StartupGate {
+	return &StartupGate{}
+}
+
+// SetReady empieza a atender todas las solicitudes con handler.
+func (g *StartupGate) SetReady(handler http.Handler) {
+	g.handler.Store(&handler)
+}
+
+// Ready indica si la API terminó de arrancar.
+func (g *StartupGate) Ready() bool {
+	return g.handler.Load() != nil
+}
+
+func (g *StartupGate) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if handler := g.handler.Load(); handler != nil {
+		(*handler).ServeHTTP(w, r)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/ping":
+		pingHandler()(w, r)
+	case "/live":
+		liveHandler()(w, r)
+	case "/health", "/ready":
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"status": "starting", "database": "unavailable"}`))
+	default:
+		w.Header().Set("Retry-After", "5")
+		response.Error(w, http.StatusServiceUnavailable, "el servicio está iniciando, intente más tarde")
+	}
+}
@@ -0,0 +1,56 @@
+package routes
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// routeTimeouts reemplaza el ReadTimeout y el WriteTimeout del servidor en
+// las rutas con nombre indicadas (por ejemplo "users.import"); 0 quita el límite.
+// El tiempo de lectura cubre el cuerpo de la solicitud, como un archivo subido.
+func routeTimeouts(router *mux.Router, reads, writes map[string]time.Duration) mux.MiddlewareFunc {
+	for name := range reads {
+		if router.Get(name) == nil {
+			log.Printf("Warning: tiempo de lectura configurado para una ruta inexistente: %s", name)
+		}
+	}
+	for name := range writes {
+		if router.Get(name) == nil {
+			log.Printf("Warning: tiempo de escritura configurado para una ruta inexistente: %s", name)
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := mux.CurrentRoute(r)
+			if route == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			controller := http.NewResponseController(w)
+			if timeout, ok := reads[route.GetName()]; ok {
+				if err := controller.SetReadDeadline(deadline(timeout)); err != nil {
+					log.Printf("no se pudo ajustar el tiempo de lectura de %s: %v", route.GetName(), err)
+				}
+			}
+			if timeout, ok := writes[route.GetName()]; ok {
+				if err := controller.SetWriteDeadline(deadline(timeout)); err != nil {
+					log.Printf("no se pudo ajustar el tiempo de escritura de %s: %v", route.GetName(), err)
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// deadline devuelve el plazo para timeout desde ahora; el tiempo cero quita el límite.
+func deadline(timeout time.Duration) time.Time {
+	if timeout <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(timeout)
+}
@@ -0,0 +1,59 @@
+package routes
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func TestRouteReadTimeouts(t *testing.T) {
+	router := mux.NewRouter()
+	read := func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusRequestTimeout)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+	router.HandleFunc("/import", read).Name("users.import")
+	router.HandleFunc("/other", read).Name("users.other")
+	router.Use(routeTimeouts(router, map[string]time.Duration{"users.import": time.Second}, nil))
+
+	server := httptest.NewUnstartedServer(router)
+	server.Config.ReadTimeout = 100 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{path: "/import", want: http.StatusNoContent},
+		{path: "/other", want: http.StatusRequestTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			// El cuerpo llega después del ReadTimeout del servidor
+			body, writer := io.Pipe()
+			go func() {
+				time.Sleep(300 * time.Millisecond)
+				writer.Write([]byte("datos"))
+				writer.Close()
+			}()
+
+			resp, err := http.Post(server.URL+tt.path, "text/plain", body)
+			if err != nil {
+				t.Fatalf("Post: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, se esperaba %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
@@ -7,14 +7,23 @@ import (
 )
 
 // SetupUserRoutes configura las rutas específicas de usuarios
-func SetupUserRoutes(router *mux.Router, userHandler *handlers.UserHandler) {
+func SetupUserRoutes(router *mux.Router, userHandler *handlers.UserHandler, middlewares ...mux.MiddlewareFunc) {
 	users := router.PathPrefix("/users").Subrouter()
+	users.Use(middlewares...)
 
 	users.HandleFunc("", userHandler.CreateUser).Methods("POST")
 	users.HandleFunc("", userHandler.GetAllUsers).Methods("GET")
+	users.HandleFunc("/purge", userHandler.PurgeUsers).Methods("POST").Name("users.purge")
+	users.HandleFunc("/bulk", userHandler.BulkCreateUsers).Methods("POST").Name("users.bulk_create")
+	users.HandleFunc("/bulk", userHandler.BulkPatchUsers).Methods("PATCH").Name("users.bulk_patch")
+	users.HandleFunc("/bulk", userHandler.BulkDeleteUsers).Methods("DELETE").Name("users.bulk_delete")
+	users.HandleFunc("/export", userHandler.ExportUsers).Methods("GET").Name("users.export")
+	users.HandleFunc("/import", userHandler.ImportUsers).Methods("POST").Name("users.import")
 	users.HandleFunc("/{id}", userHandler.GetUserByID).Methods("GET")
 	users.HandleFunc("/{id}", userHandler.UpdateUser).Methods("PUT")
+	users.HandleFunc("/{id}", userHandler.PatchUser).Methods("PATCH")
 	users.HandleFunc("/{id}", userHandler.DeleteUser).Methods("DELETE")
+	users.HandleFunc("/{id}/restore", userHandler.RestoreUser).Methods("POST")
 
 	// Rutas adicionales específicas de usuarios
 	users.HandleFunc("/email/{email}", userHandler.GetByEmail).Methods("GET")
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"pt-brm/internal/auth"
+	"pt-brm/internal/models"
+	"pt-brm/internal/repositories"
+	"time"
+)
+
+// lastUsedInterval limita la frecuencia con la que se registra el último uso
+// de una clave, para no escribir en la base de datos en cada solicitud.
+const lastUsedInterval = time.Minute
+
+// Errores de autenticación con API key; se responden con 401 sin dar más
+// detalles al cliente.
+var (
+	ErrInvalidAPIKey = errors.New("API key inválida")
+	ErrAPIKeyExpired = errors.New("API key expirada")
+)
+
+type APIKeyService interface {
+	CreateKey(ctx context.Context, req *models.CreateAPIKeyRequest) (*models.IssuedAPIKey, error)
+	ListKeys(ctx context.Context) ([]*models.APIKey, error)
+	RevokeKey(ctx context.Context, id int) (*models.APIKey, error)
+	RotateKey(ctx context.Context, id int) (*models.IssuedAPIKey, error)
+	Authenticate(ctx context.Context, key string) (*models.APIKey, error)
+}
+
+type apiKeyService struct {
+	repo repositories.APIKeyRepository
+}
+
+func NewAPIKeyService(repo repositories.APIKeyRepository) APIKeyService {
+	return &apiKeyService{repo: repo}
+}
+
+func (s *apiKeyService) CreateKey(ctx context.Context, req *models.CreateAPIKeyRequest) (*models.IssuedAPIKey, error) {
+	if err := req.Validate(time.Now()); err != nil {
+		return nil, err
+	}
+
+	secret, err := auth.NewAPIKeySecret()
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo generar la API key: %w", err)
+	}
+
+	key, err := s.repo.Create(ctx, &models.APIKey{
+		Name:      req.Name,
+		Prefix:    secret.Prefix,
+		Salt:      secret.Salt,
+		Hash:      secret.Hash,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.IssuedAPIKey{APIKey: key, Key: secret.Key}, nil
+}
+
+func (s *apiKeyService) ListKeys(ctx context.Context) ([]*models.APIKey, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *apiKeyService) RevokeKey(ctx context.Context, id int) (*models.APIKey, error) {
+	return s.repo.Revoke(ctx, id)
+}
+
+// RotateKey genera un secreto nuevo para la clave conservando su nombre,
+// scopes y expiración.
+func (s *apiKeyService) RotateKey(ctx context.Context, id int) (*models.IssuedAPIKey, error) {
+	secret, err := auth.NewAPIKeySecret()
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo generar la API key: %w", err)
+	}
+
+	key, err := s.repo.Rotate(ctx, id, secret.Prefix, secret.Salt, secret.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.IssuedAPIKey{APIKey: key, Key: secret.Key}, nil
+}
+
+// Authenticate devuelve la clave activa que corresponde al secreto
+// presentado y registra su uso.
+func (s *apiKeyService) Authenticate(ctx context.Context, raw string) (*models.APIKey, error) {
+	prefix, secret, ok := auth.SplitAPIKey(raw)
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.repo.GetByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	if !auth.CheckAPIKeySecret(secret, key.Salt, key.Hash) || key.RevokedAt != nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if !key.Active(now) {
+		return nil, ErrAPIKeyExpired
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedInterval {
+		// No registrar el uso no debe impedir la solicitud
+		if err := s.repo.TouchLastUsed(ctx, key.ID, now); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+
+	return key, nil
+}
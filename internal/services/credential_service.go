@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"pt-brm/internal/auth"
+	"pt-brm/internal/models"
+	"pt-brm/internal/repositories"
+)
+
+var (
+	// ErrInvalidCredentials se devuelve tanto si el email no existe como si la
+	// contraseña no coincide, para no revelar qué usuarios existen.
+	ErrInvalidCredentials = errors.New("credenciales inválidas")
+	// ErrPasswordNotSet se devuelve cuando una solicitud que debe probar la
+	// contraseña actual intenta asignar la primera contraseña de un usuario.
+	ErrPasswordNotSet = errors.New("el usuario no tiene contraseña; solo un administrador puede asignarla")
+)
+
+// PasswordChange indica quién cambia la contraseña y qué se le exige.
+type PasswordChange int
+
+const (
+	// ChangeOwnPassword: el propio usuario, con un token que tiene users:write.
+	// Debe enviar la contraseña actual si ya tiene una.
+	ChangeOwnPassword PasswordChange = iota
+	// ChangeWithCurrentPassword: sin autenticación (AUTH_ENABLED=false) o el
+	// propio usuario con un token sin users:write. Siempre debe enviar la
+	// contraseña actual, por lo que no puede asignar la primera.
+	ChangeWithCurrentPassword
+	// ResetPassword: un administrador asigna la contraseña sin la actual.
+	ResetPassword
+)
+
+type CredentialService interface {
+	Login(ctx context.Context, req *models.LoginRequest) (*models.User, error)
+	ChangePassword(ctx context.Context, id int, req *models.ChangePasswordRequest, change PasswordChange) error
+}
+
+type credentialService struct {
+	userRepo repositories.UserRepository
+	hasher   *auth.PasswordHasher
+	policy   *auth.PasswordPolicy
+	// dummyHash se verifica cuando el usuario no existe, para que la respuesta
+	// tarde lo mismo que con una contraseña incorrecta
+	dummyHash string
+}
+
+func NewCredentialService(userRepo repositories.UserRepository, hasher *auth.PasswordHasher, policy *auth.PasswordPolicy) CredentialService {
+	// Hash solo falla si falla crypto/rand, que desde Go 1.24 no devuelve errores
+	dummyHash, _ := hasher.Hash(context.Background(), "contraseña de relleno")
+	return &credentialService{
+		userRepo:  userRepo,
+		hasher:    hasher,
+		policy:    policy,
+		dummyHash: dummyHash,
+	}
+}
+
+// Login verifica el email y la contraseña. Si el hash usa bcrypt o parámetros
+// de argon2id anteriores, lo regenera con los actuales.
+func (s *credentialService) Login(ctx context.Context, req *models.LoginRequest) (*models.User, error) {
+	// Ninguna contraseña guardada supera la longitud máxima
+	if s.policy.TooLong(req.Password) {
+		return nil, ErrInvalidCredentials
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, req.Email)
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
+		return nil, err
+	}
+
+	var hash string
+	if user != nil {
+		hash, err = s.userRepo.GetPasswordHash(ctx, user.ID)
+		if err != nil && !errors.Is(err, models.ErrNotFound) {
+			return nil, err
+		}
+	}
+	if hash == "" {
+		s.hasher.Verify(ctx, req.Password, s.dummyHash)
+		return nil, ErrInvalidCredentials
+	}
+
+	ok, rehash, err := s.hasher.Verify(ctx, req.Password, hash)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo verificar la contraseña del usuario %d: %w", user.ID, err)
+	}
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+
+	if rehash {
+		// Si falla se vuelve a intentar en el próximo inicio de sesión
+		if newHash, err := s.hasher.Hash(ctx, req.Password); err != nil {
+			log.Printf("Warning: no se pudo regenerar el hash del usuario %d: %v", user.ID, err)
+		} else if err := s.userRepo.SetPasswordHash(ctx, user.ID, newHash); err != nil {
+			log.Printf("Warning: no se pudo actualizar el hash del usuario %d: %v", user.ID, err)
+		}
+	}
+
+	return user, nil
+}
+
+// ChangePassword asigna una contraseña nueva que cumpla la política, exigiendo
+// la actual según change.
+func (s *credentialService) ChangePassword(ctx context.Context, id int, req *models.ChangePasswordRequest, change PasswordChange) error {
+	hash, err := s.userRepo.GetPasswordHash(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if change == ChangeWithCurrentPassword && hash == "" {
+		return ErrPasswordNotSet
+	}
+	if change != ResetPassword && hash != "" {
+		if req.CurrentPassword == "" {
+			return models.NewValidationError("current_password_required", "current_password", "la contraseña actual es requerida")
+		}
+		if s.policy.TooLong(req.CurrentPassword) {
+			return models.ErrInvalidCurrentPassword
+		}
+		ok, _, err := s.hasher.Verify(ctx, req.CurrentPassword, hash)
+		if err != nil {
+			return fmt.Errorf("no se pudo verificar la contraseña del usuario %d: %w", id, err)
+		}
+		if !ok {
+			return models.ErrInvalidCurrentPassword
+		}
+	}
+
+	if err := s.policy.Check("new_password", req.NewPassword); err != nil {
+		return err
+	}
+
+	newHash, err := s.hasher.Hash(ctx, req.NewPassword)
+	if err != nil {
+		return fmt.Errorf("no se pudo generar el hash de la contraseña: %w", err)
+	}
+	return s.userRepo.SetPasswordHash(ctx, id, newHash)
+}
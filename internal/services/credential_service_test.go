@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"pt-brm/internal/auth"
+	"pt-brm/internal/config"
+	"pt-brm/internal/models"
+	"pt-brm/internal/repositories"
+)
+
+func TestLogin(t *testing.T) {
+	cfg := config.PasswordConfig{MinLength: 12, MaxLength: 64, Argon2Memory: 8192, Argon2Iterations: 1, Argon2Parallelism: 1, MaxConcurrent: 1}
+	hasher := auth.NewPasswordHasher(cfg)
+	policy, err := auth.NewPasswordPolicy(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	repo := repositories.NewMemoryUserRepository()
+	service := NewCredentialService(repo, hasher, policy)
+
+	// Una contraseña más larga que el máximo guardada antes de bajar PASSWORD_MAX_LENGTH
+	long := strings.Repeat("x", 65)
+	for _, u := range []struct{ email, password string }{
+		{"ana@example.com", "correcto caballo batería"},
+		{"eva@example.com", long},
+	} {
+		user, err := repo.Create(ctx, &models.User{Name: "Usuario", Email: u.email, Age: 30})
+		if err != nil {
+			t.Fatal(err)
+		}
+		hash, err := hasher.Hash(ctx, u.password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.SetPasswordHash(ctx, user.ID, hash); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := repo.Create(ctx, &models.User{Name: "Sin contraseña", Email: "leo@example.com", Age: 30}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		wantErr  error
+	}{
+		{name: "correcto", email: "ana@example.com", password: "correcto caballo batería"},
+		{name: "contraseña incorrecta", email: "ana@example.com", password: "incorrecto caballo batería", wantErr: ErrInvalidCredentials},
+		{name: "email desconocido", email: "nadie@example.com", password: "correcto caballo batería", wantErr: ErrInvalidCredentials},
+		{name: "usuario sin contraseña", email: "leo@example.com", password: "correcto caballo batería", wantErr: ErrInvalidCredentials},
+		{name: "contraseña demasiado larga", email: "eva@example.com", password: long, wantErr: ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := service.Login(ctx, &models.LoginRequest{Email: tt.email, Password: tt.password})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Login = %v, se esperaba %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && user.Email != tt.email {
+				t.Errorf("Login devolvió %s, se esperaba %s", user.Email, tt.email)
+			}
+		})
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	cfg := config.PasswordConfig{MinLength: 12, MaxLength: 64, Argon2Memory: 8192, Argon2Iterations: 1, Argon2Parallelism: 1, MaxConcurrent: 1}
+	hasher := auth.NewPasswordHasher(cfg)
+	policy, err := auth.NewPasswordPolicy(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const current = "correcto caballo batería"
+	const next = "una frase larga y segura"
+
+	tests := []struct {
+		name        string
+		hasPassword bool
+		change      PasswordChange
+		current     string
+		wantErr     error
+	}{
+		{name: "propia con la actual", hasPassword: true, change: ChangeOwnPassword, current: current},
+		{name: "propia sin la actual", hasPassword: true, change: ChangeOwnPassword, wantErr: models.ErrValidation},
+		{name: "propia con la actual incorrecta", hasPassword: true, change: ChangeOwnPassword, current: "otra contraseña", wantErr: models.ErrInvalidCurrentPassword},
+		{name: "propia, primera contraseña", change: ChangeOwnPassword},
+		{name: "con la actual", hasPassword: true, change: ChangeWithCurrentPassword, current: current},
+		{name: "sin la actual", hasPassword: true, change: ChangeWithCurrentPassword, wantErr: models.ErrValidation},
+		{name: "primera contraseña sin permiso", change: ChangeWithCurrentPassword, wantErr: ErrPasswordNotSet},
+		{name: "administrador sin la actual", hasPassword: true, change: ResetPassword},
+		{name: "administrador, primera contraseña", change: ResetPassword},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := repositories.NewMemoryUserRepository()
+			service := NewCredentialService(repo, hasher, policy)
+
+			user, err := repo.Create(ctx, &models.User{Name: "Ana", Email: "ana@example.com", Age: 30})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.hasPassword {
+				hash, err := hasher.Hash(ctx, current)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := repo.SetPasswordHash(ctx, user.ID, hash); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			req := &models.ChangePasswordRequest{CurrentPassword: tt.current, NewPassword: next}
+			err = service.ChangePassword(ctx, user.ID, req, tt.change)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ChangePassword = %v, se esperaba %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if _, err := service.Login(ctx, &models.LoginRequest{Email: user.Email, Password: next}); err != nil {
+				t.Errorf("Login con la contraseña nueva = %v", err)
+			}
+		})
+	}
+}
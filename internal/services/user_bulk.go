@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"pt-brm/internal/models"
+)
+
+func (s *userService) BulkCreateUsers(ctx context.Context, reqs []models.CreateUserRequest, mode models.BulkMode) (*models.BulkResult, error) {
+	if err := models.ValidateBulkSize(len(reqs)); err != nil {
+		return nil, err
+	}
+
+	// Validar cada usuario antes de tocar la base de datos
+	users := make([]*models.User, len(reqs))
+	errs := make([]error, len(reqs))
+	var valid []*models.User
+	var positions []int
+
+	for i, req := range reqs {
+		user := &models.User{
+			Name:  req.Name,
+			Email: req.Email,
+			Age:   req.Age,
+		}
+		if err := user.Validate(); err != nil {
+			errs[i] = err
+			continue
+		}
+		valid = append(valid, user)
+		positions = append(positions, i)
+	}
+
+	// En modo atómico un solo elemento inválido cancela toda la operación
+	if len(valid) > 0 && (mode == models.BulkBestEffort || len(valid) == len(reqs)) {
+		created, repoErrs, err := s.userRepo.CreateMany(ctx, valid, mode == models.BulkAtomic)
+		if err != nil {
+			return nil, err
+		}
+		for j, i := range positions {
+			users[i], errs[i] = created[j], repoErrs[j]
+		}
+	}
+
+	return models.NewBulkResult(mode, models.BulkStatusCreated, users, nil, errs), nil
+}
+
+func (s *userService) BulkPatchUsers(ctx context.Context, items []models.BulkPatchItem, mode models.BulkMode) (*models.BulkResult, error) {
+	if err := models.ValidateBulkSize(len(items)); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+
+	// Obtener todos los usuarios existentes de una vez
+	current, err := s.userRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]*models.User, len(items))
+	errs := duplicateIDErrors(ids)
+	var valid []*models.User
+	var positions []int
+
+	for i, item := range items {
+		if errs[i] != nil {
+			continue
+		}
+
+		user, ok := current[item.ID]
+		switch {
+		case !ok:
+			errs[i] = models.ErrUserNotFound
+		case item.Version != 0 && item.Version != user.Version:
+			errs[i] = models.ErrPreconditionFailed
+		default:
+			// Aplicar los cambios y validar el resultado completo
+			if err := item.Changes.ApplyTo(user); err != nil {
+				errs[i] = err
+			} else if err := user.Validate(); err != nil {
+				errs[i] = err
+			}
+		}
+
+		if errs[i] == nil {
+			valid = append(valid, user)
+			positions = append(positions, i)
+		}
+	}
+
+	if len(valid) > 0 && (mode == models.BulkBestEffort || len(valid) == len(items)) {
+		updated, repoErrs, err := s.userRepo.UpdateMany(ctx, valid, mode == models.BulkAtomic)
+		if err != nil {
+			return nil, err
+		}
+		for j, i := range positions {
+			users[i], errs[i] = updated[j], repoErrs[j]
+		}
+	}
+
+	return models.NewBulkResult(mode, models.BulkStatusUpdated, users, ids, errs), nil
+}
+
+func (s *userService) BulkDeleteUsers(ctx context.Context, ids []int, mode models.BulkMode) (*models.BulkResult, error) {
+	if err := models.ValidateBulkSize(len(ids)); err != nil {
+		return nil, err
+	}
+
+	errs := duplicateIDErrors(ids)
+	var valid []int
+	var positions []int
+
+	for i, id := range ids {
+		if errs[i] == nil {
+			valid = append(valid, id)
+			positions = append(positions, i)
+		}
+	}
+
+	if len(valid) > 0 && (mode == models.BulkBestEffort || len(valid) == len(ids)) {
+		repoErrs, err := s.userRepo.DeleteMany(ctx, valid, mode == models.BulkAtomic)
+		if err != nil {
+			return nil, err
+		}
+		for j, i := range positions {
+			errs[i] = repoErrs[j]
+		}
+	}
+
+	return models.NewBulkResult(mode, models.BulkStatusDeleted, nil, ids, errs), nil
+}
+
+// duplicateIDErrors marca como inválidas las apariciones repetidas de un mismo id.
+func duplicateIDErrors(ids []int) []error {
+	errs := make([]error, len(ids))
+	seen := make(map[int]int, len(ids))
+
+	for i, id := range ids {
+		if first, ok := seen[id]; ok {
+			errs[i] = models.NewValidationError("duplicate_id", "id", fmt.Sprintf("el id %d ya aparece en la posición %d", id, first))
+			continue
+		}
+		seen[id] = i
+	}
+
+	return errs
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"pt-brm/internal/models"
+	"strings"
+)
+
+// Cantidad de filas válidas que se acumulan antes de insertarlas.
+const importBatchSize = 500
+
+func (s *userService) ExportUsers(ctx context.Context, params *models.UserListParams, fn func(user *models.User) error) error {
+	// Recorrer los usuarios del repositorio sin cargarlos todos en memoria
+	return s.userRepo.Export(ctx, params, fn)
+}
+
+// ImportUsers lee registros con next hasta io.EOF, valida cada uno con
+// User.Validate y, salvo en dryRun, inserta en lotes los válidos. En dryRun
+// solo se buscan los emails que ya existen, para reportar los mismos
+// conflictos que la importación real. Las filas que fallan se reportan con su
+// número de línea y no detienen la importación.
+func (s *userService) ImportUsers(ctx context.Context, next func() (*models.ImportRecord, error), dryRun bool) (*models.ImportReport, error) {
+	report := &models.ImportReport{DryRun: dryRun, Errors: []models.ImportLineError{}}
+	seen := make(map[string]int)
+
+	var batch []*models.User
+	var lines []int
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		var errs []error
+		var err error
+		if dryRun {
+			errs, err = s.existingEmails(ctx, batch)
+		} else {
+			_, errs, err = s.userRepo.CreateMany(ctx, batch, false)
+		}
+		if err != nil {
+			return err
+		}
+		for i, err := range errs {
+			if err != nil {
+				report.AddError(lines[i], err)
+			} else {
+				report.Imported++
+			}
+		}
+
+		batch, lines = batch[:0], lines[:0]
+		return nil
+	}
+
+	for {
+		record, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		report.Total++
+		if record.Err != nil {
+			report.AddError(record.Line, record.Err)
+			continue
+		}
+
+		user := &models.User{
+			Name:  record.Request.Name,
+			Email: record.Request.Email,
+			Age:   record.Request.Age,
+		}
+		if err := user.Validate(); err != nil {
+			report.AddError(record.Line, err)
+			continue
+		}
+
+		// Detectar emails repetidos dentro del mismo archivo, también en dry-run
+		key := strings.ToLower(user.Email)
+		if first, ok := seen[key]; ok {
+			conflict := models.NewConflictError("duplicate_email_in_file", fmt.Sprintf("el email ya aparece en la línea %d", first))
+			conflict.Field = "email"
+			report.AddError(record.Line, conflict)
+			continue
+		}
+		seen[key] = record.Line
+
+		batch = append(batch, user)
+		lines = append(lines, record.Line)
+		if len(batch) >= importBatchSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// existingEmails devuelve, alineado con users, ErrEmailAlreadyExists para los
+// usuarios cuyo email ya pertenece a un usuario activo.
+func (s *userService) existingEmails(ctx context.Context, users []*models.User) ([]error, error) {
+	emails := make([]string, len(users))
+	for i, user := range users {
+		emails[i] = user.Email
+	}
+
+	existing, err := s.userRepo.GetByEmails(ctx, emails)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := make([]error, len(users))
+	for i, user := range users {
+		if _, ok := existing[strings.ToLower(user.Email)]; ok {
+			errs[i] = models.ErrEmailAlreadyExists
+		}
+	}
+	return errs, nil
+}
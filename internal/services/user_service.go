@@ -1,30 +1,50 @@
 package services
 
 import (
+	"context"
 	"pt-brm/internal/models"
 	"pt-brm/internal/repositories"
+	"slices"
+	"time"
 )
 
 type UserService interface {
-	CreateUser(req *models.CreateUserRequest) (*models.User, error)
-	GetAllUsers() ([]*models.User, error)
-	GetUserByID(id int) (*models.User, error)
-	UpdateUser(id int, req *models.UpdateUserRequest) (*models.User, error)
-	DeleteUser(id int) error
-	GetUserByEmail(email string) (*models.User, error)
+	CreateUser(ctx context.Context, req *models.CreateUserRequest) (*models.User, error)
+	GetAllUsers(ctx context.Context) ([]*models.User, error)
+	ListUsers(ctx context.Context, params *models.UserListParams) (*models.UserPage, error)
+	GetUserByID(ctx context.Context, id int) (*models.User, error)
+	UpdateUser(ctx context.Context, id int, req *models.UpdateUserRequest, versions []int) (*models.User, error)
+	PatchUser(ctx context.Context, id int, patch models.UserPatch, versions []int) (*models.User, error)
+	DeleteUser(ctx context.Context, id int, versions []int) error
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	RestoreUser(ctx context.Context, id int) (*models.User, error)
+	PurgeDeletedUsers(ctx context.Context, retention time.Duration) (int64, error)
+	BulkCreateUsers(ctx context.Context, reqs []models.CreateUserRequest, mode models.BulkMode) (*models.BulkResult, error)
+	BulkPatchUsers(ctx context.Context, items []models.BulkPatchItem, mode models.BulkMode) (*models.BulkResult, error)
+	BulkDeleteUsers(ctx context.Context, ids []int, mode models.BulkMode) (*models.BulkResult, error)
+	ExportUsers(ctx context.Context, params *models.UserListParams, fn func(user *models.User) error) error
+	ImportUsers(ctx context.Context, next func() (*models.ImportRecord, error), dryRun bool) (*models.ImportReport, error)
+}
+
+// Transactor ejecuta fn dentro de una transacción. Los repositorios que
+// reciben el ctx de fn participan de ella.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 type userService struct {
 	userRepo repositories.UserRepository
+	tx       Transactor
 }
 
-func NewUserService(userRepo repositories.UserRepository) UserService {
+func NewUserService(userRepo repositories.UserRepository, tx Transactor) UserService {
 	return &userService{
 		userRepo: userRepo,
+		tx:       tx,
 	}
 }
 
-func (s *userService) CreateUser(req *models.CreateUserRequest) (*models.User, error) {
+func (s *userService) CreateUser(ctx context.Context, req *models.CreateUserRequest) (*models.User, error) {
 	// crear un nuevo usuario a partir de la solicitud
 	user := &models.User{
 		Name:  req.Name,
@@ -38,57 +58,132 @@ func (s *userService) CreateUser(req *models.CreateUserRequest) (*models.User, e
 	}
 
 	// Crear el usuario en el repositorio
-	return s.userRepo.Create(user)
+	return s.userRepo.Create(ctx, user)
 }
 
-func (s *userService) GetAllUsers() ([]*models.User, error) {
+func (s *userService) GetAllUsers(ctx context.Context) ([]*models.User, error) {
 	// Obtener todos los usuarios del repositorio
-	return s.userRepo.GetAll()
+	return s.userRepo.GetAll(ctx)
+}
+
+func (s *userService) ListUsers(ctx context.Context, params *models.UserListParams) (*models.UserPage, error) {
+	// Obtener la página de usuarios del repositorio
+	return s.userRepo.List(ctx, params)
 }
 
-func (s *userService) GetUserByID(id int) (*models.User, error) {
+func (s *userService) GetUserByID(ctx context.Context, id int) (*models.User, error) {
 	// Obtener un usuario por ID del repositorio
-	return s.userRepo.GetByID(id)
+	return s.userRepo.GetByID(ctx, id)
 }
 
-func (s *userService) UpdateUser(id int, req *models.UpdateUserRequest) (*models.User, error) {
-	// Obtener el usuario existente por ID
-	user, err := s.userRepo.GetByID(id)
+// Los métodos que modifican un usuario reciben las versiones aceptadas (If-Match);
+// nil indica que el cliente no pidió ninguna condición.
+
+func (s *userService) UpdateUser(ctx context.Context, id int, req *models.UpdateUserRequest, versions []int) (*models.User, error) {
+	var updated *models.User
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// Obtener el usuario existente por ID
+		user, err := s.getForWrite(ctx, id, versions)
+		if err != nil {
+			return err
+		}
+
+		// Actualizar los campos del usuario con los datos de la solicitud
+		user.Name = req.Name
+		user.Email = req.Email
+		user.Age = req.Age
+
+		// Validar el usuario actualizado
+		if err := user.Validate(); err != nil {
+			return err
+		}
+
+		// Actualizar el usuario en el repositorio
+		updated, err = s.userRepo.Update(ctx, id, user)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Actualizar los campos del usuario con los datos de la solicitud
-	user.Name = req.Name
-	user.Email = req.Email
-	user.Age = req.Age
+	return updated, nil
+}
 
-	// Validar el usuario actualizado
-	if err := user.Validate(); err != nil {
+func (s *userService) PatchUser(ctx context.Context, id int, patch models.UserPatch, versions []int) (*models.User, error) {
+	var updated *models.User
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// Obtener el usuario existente por ID
+		user, err := s.getForWrite(ctx, id, versions)
+		if err != nil {
+			return err
+		}
+
+		// Aplicar solo los cambios enviados por el cliente
+		if err := patch.ApplyTo(user); err != nil {
+			return err
+		}
+
+		// Validar el resultado completo, no solo los campos modificados
+		if err := user.Validate(); err != nil {
+			return err
+		}
+
+		// Actualizar el usuario en el repositorio
+		updated, err = s.userRepo.Update(ctx, id, user)
+		return err
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	// Actualizar el usuario en el repositorio
-	return s.userRepo.Update(id, user)
+	return updated, nil
 }
 
-func (s *userService) DeleteUser(id int) error {
-	// Verificar si el usuario existe antes de eliminar
-	_, err := s.userRepo.GetByID(id)
-	if err != nil {
-		return err
-	}
-
-	// Eliminar el usuario por ID del repositorio
-	return s.userRepo.Delete(id)
+func (s *userService) DeleteUser(ctx context.Context, id int, versions []int) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// Verificar si el usuario existe antes de eliminar
+		user, err := s.getForWrite(ctx, id, versions)
+		if err != nil {
+			return err
+		}
+
+		// Eliminar el usuario por ID del repositorio, solo si nadie lo modificó entre tanto
+		return s.userRepo.Delete(ctx, id, user.Version)
+	})
 }
 
-func (s *userService) GetUserByEmail(email string) (*models.User, error) {
+func (s *userService) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	// Validar el formato del email antes de buscar
 	if !models.IsValidEmail(email) {
 		return nil, models.ErrInvalidEmailFormat
 	}
 
 	// Obtener el usuario por email del repositorio
-	return s.userRepo.GetByEmail(email)
+	return s.userRepo.GetByEmail(ctx, email)
+}
+
+func (s *userService) RestoreUser(ctx context.Context, id int) (*models.User, error) {
+	// Restaurar el usuario eliminado en el repositorio
+	return s.userRepo.Restore(ctx, id)
+}
+
+func (s *userService) PurgeDeletedUsers(ctx context.Context, retention time.Duration) (int64, error) {
+	// Eliminar definitivamente los usuarios que llevan más tiempo eliminados que la retención
+	return s.userRepo.PurgeDeleted(ctx, time.Now().UTC().Add(-retention))
+}
+
+// getForWrite obtiene el usuario y verifica que esté en alguna de las versiones esperadas.
+// El repositorio vuelve a comprobar la versión al escribir, de modo que una
+// modificación concurrente entre la lectura y la escritura también se detecta.
+func (s *userService) getForWrite(ctx context.Context, id int, versions []int) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if versions != nil && !slices.Contains(versions, user.Version) {
+		return nil, models.ErrPreconditionFailed
+	}
+
+	return user, nil
 }
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"pt-brm/internal/models"
+	"pt-brm/internal/repositories"
+)
+
+func TestUpdateUserIfMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions []int
+		wantErr  error
+	}{
+		{name: "sin condición", versions: nil},
+		{name: "versión actual", versions: []int{1}},
+		{name: "lista con la versión actual", versions: []int{4, 1, 7}},
+		{name: "versión antigua", versions: []int{2}, wantErr: models.ErrPreconditionFailed},
+		{name: "lista sin la versión actual", versions: []int{2, 3}, wantErr: models.ErrPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := repositories.NewMemoryUserRepository()
+			service := NewUserService(repo, repo)
+
+			user, err := service.CreateUser(ctx, &models.CreateUserRequest{Name: "Ana", Email: "ana@example.com", Age: 30})
+			if err != nil {
+				t.Fatalf("CreateUser: %v", err)
+			}
+
+			req := &models.UpdateUserRequest{Name: "Eva", Email: "eva@example.com", Age: 31}
+			_, err = service.UpdateUser(ctx, user.ID, req, tt.versions)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("UpdateUser(%v) = %v, se esperaba %v", tt.versions, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestImportUsersDryRunConflicts(t *testing.T) {
+	ctx := context.Background()
+	repo := repositories.NewMemoryUserRepository()
+	service := NewUserService(repo, repo)
+
+	if _, err := service.CreateUser(ctx, &models.CreateUserRequest{Name: "Ana", Email: "ana@example.com", Age: 30}); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	for _, dryRun := range []bool{true, false} {
+		records := []*models.ImportRecord{
+			{Line: 2, Request: models.CreateUserRequest{Name: "Otra Ana", Email: "ANA@example.com", Age: 25}},
+			{Line: 3, Request: models.CreateUserRequest{Name: "Luis", Email: "luis@example.com", Age: 40}},
+		}
+		next := func() (*models.ImportRecord, error) {
+			if len(records) == 0 {
+				return nil, io.EOF
+			}
+			record := records[0]
+			records = records[1:]
+			return record, nil
+		}
+
+		report, err := service.ImportUsers(ctx, next, dryRun)
+		if err != nil {
+			t.Fatalf("ImportUsers(dryRun=%v): %v", dryRun, err)
+		}
+		if report.Imported != 1 || report.Failed != 1 {
+			t.Errorf("ImportUsers(dryRun=%v): importados %d, fallidos %d; se esperaban 1 y 1", dryRun, report.Imported, report.Failed)
+		}
+		if len(report.Errors) == 1 && (report.Errors[0].Line != 2 || report.Errors[0].Code != "email_already_exists") {
+			t.Errorf("ImportUsers(dryRun=%v): error = %+v, se esperaba email_already_exists en la línea 2", dryRun, report.Errors[0])
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package response
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"pt-brm/internal/models"
+)
+
+// StatusClientClosedRequest es el código no estándar (popularizado por nginx)
+// que se usa cuando el cliente cerró la conexión antes de recibir la respuesta.
+const StatusClientClosedRequest = 499
+
+// FromError traduce un error del dominio a su respuesta HTTP. Los errores que
+// no pertenecen a ninguna categoría conocida se tratan como internos: se
+// registran en el log y al cliente solo se le entrega un mensaje genérico.
+func FromError(w http.ResponseWriter, err error) {
+	status := StatusFromError(err)
+
+	// Cancelaciones y tiempos agotados no son fallas del servidor
+	switch status {
+	case StatusClientClosedRequest:
+		write(w, status, APIResponse{Success: false, Code: "request_canceled", Error: "la solicitud fue cancelada"})
+		return
+	case http.StatusServiceUnavailable:
+		write(w, status, APIResponse{Success: false, Code: "timeout", Error: "la operación superó el tiempo máximo"})
+		return
+	}
+
+	var domainErr *models.DomainError
+	if status == http.StatusInternalServerError || !errors.As(err, &domainErr) {
+		log.Printf("error interno: %v", err)
+		write(w, status, APIResponse{
+			Success: false,
+			Code:    "internal_error",
+			Error:   "error interno del servidor",
+		})
+		return
+	}
+
+	write(w, status, APIResponse{
+		Success: false,
+		Code:    domainErr.Code,
+		Field:   domainErr.Field,
+		Error:   domainErr.Message,
+	})
+}
+
+// StatusFromError devuelve el código HTTP que corresponde a la categoría del error.
+func StatusFromError(err error) int {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return StatusClientClosedRequest
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, models.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, models.ErrValidation):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, models.ErrPrecondition):
+		return http.StatusPreconditionFailed
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// codeFromStatus da un código genérico a los errores que se responden directamente con Error.
+func codeFromStatus(statusCode int) string {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return "bad_request"
+	case http.StatusUnauthorized:
+		return "unauthorized"
+	case http.StatusForbidden:
+		return "forbidden"
+	case http.StatusNotFound:
+		return "not_found"
+	case http.StatusUnsupportedMediaType:
+		return "unsupported_media_type"
+	case http.StatusTooManyRequests:
+		return "rate_limited"
+	case http.StatusServiceUnavailable:
+		return "service_unavailable"
+	case http.StatusInternalServerError:
+		return "internal_error"
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,102 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pt-brm/internal/models"
+)
+
+func TestFromError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+		wantField  string
+		wantError  string
+	}{
+		{
+			name:       "no encontrado",
+			err:        models.ErrUserNotFound,
+			wantStatus: http.StatusNotFound,
+			wantCode:   models.ErrUserNotFound.Code,
+			wantError:  models.ErrUserNotFound.Message,
+		},
+		{
+			name:       "conflicto envuelto",
+			err:        fmt.Errorf("no se pudo crear el usuario: %w", models.ErrEmailAlreadyExists),
+			wantStatus: http.StatusConflict,
+			wantCode:   models.ErrEmailAlreadyExists.Code,
+			wantField:  "email",
+			wantError:  models.ErrEmailAlreadyExists.Message,
+		},
+		{
+			name:       "validación con campo",
+			err:        models.NewValidationError("value_too_long", "name", "el valor de name es demasiado largo"),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   "value_too_long",
+			wantField:  "name",
+			wantError:  "el valor de name es demasiado largo",
+		},
+		{
+			name:       "precondición",
+			err:        models.ErrPreconditionFailed,
+			wantStatus: http.StatusPreconditionFailed,
+			wantCode:   models.ErrPreconditionFailed.Code,
+			wantError:  models.ErrPreconditionFailed.Message,
+		},
+		{
+			name:       "error desconocido",
+			err:        errors.New("dial tcp 10.0.0.1:3306: connection refused"),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   "internal_error",
+			wantError:  "error interno del servidor",
+		},
+		{
+			name:       "error interno del dominio",
+			err:        models.NewInternalError("no se pudo leer", errors.New("disco lleno")),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   "internal_error",
+			wantError:  "error interno del servidor",
+		},
+		{
+			name:       "cancelado",
+			err:        fmt.Errorf("no se pudo listar: %w", context.Canceled),
+			wantStatus: StatusClientClosedRequest,
+			wantCode:   "request_canceled",
+			wantError:  "la solicitud fue cancelada",
+		},
+		{
+			name:       "tiempo agotado",
+			err:        context.DeadlineExceeded,
+			wantStatus: http.StatusServiceUnavailable,
+			wantCode:   "timeout",
+			wantError:  "la operación superó el tiempo máximo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			FromError(w, tt.err)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, se esperaba %d", w.Code, tt.wantStatus)
+			}
+
+			var body APIResponse
+			if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+				t.Fatalf("no se pudo decodificar la respuesta: %v", err)
+			}
+			if body.Success || body.Code != tt.wantCode || body.Field != tt.wantField || body.Error != tt.wantError {
+				t.Errorf("respuesta = %+v, se esperaba code=%q field=%q error=%q", body, tt.wantCode, tt.wantField, tt.wantError)
+			}
+		})
+	}
+}
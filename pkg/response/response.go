@@ -6,10 +6,21 @@ import (
 )
 
 type APIResponse struct {
-	Success bool   `json:"success"`
-	Message string `json:"message,omitempty"`
-	Data    any    `json:"data,omitempty"`
-	Error   string `json:"error,omitempty"`
+	Success    bool        `json:"success"`
+	Message    string      `json:"message,omitempty"`
+	Data       any         `json:"data,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	Code       string      `json:"code,omitempty"`
+	Field      string      `json:"field,omitempty"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+// Pagination describe la página entregada en un listado.
+type Pagination struct {
+	Total      int    `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 func JSON(w http.ResponseWriter, statusCode int, data any) {
@@ -24,14 +35,31 @@ func JSON(w http.ResponseWriter, statusCode int, data any) {
 	json.NewEncoder(w).Encode(response)
 }
 
-func Error(w http.ResponseWriter, statusCode int, message string) {
+// Paginated responde un listado junto con la información de paginación.
+func Paginated(w http.ResponseWriter, statusCode int, data any, pagination Pagination) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 
 	response := APIResponse{
+		Success:    statusCode < 400,
+		Data:       data,
+		Pagination: &pagination,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+func Error(w http.ResponseWriter, statusCode int, message string) {
+	write(w, statusCode, APIResponse{
 		Success: false,
 		Error:   message,
-	}
+		Code:    codeFromStatus(statusCode),
+	})
+}
+
+func write(w http.ResponseWriter, statusCode int, response APIResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
 
 	json.NewEncoder(w).Encode(response)
 }
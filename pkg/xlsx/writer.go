@@ -0,0 +1,129 @@
+// Package xlsx escribe planillas de Excel (Office Open XML) de una sola hoja
+// en modo streaming: las filas se escriben a medida que llegan, sin mantener
+// el documento en memoria.
+package xlsx
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Partes fijas del paquete; la hoja se escribe al final porque es la única
+// que crece con los datos.
+var staticParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+}
+
+const workbookTemplate = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+// ContentType es el tipo MIME de los archivos .xlsx.
+const ContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// Writer escribe las filas de una hoja.
+type Writer struct {
+	zw    *zip.Writer
+	sheet *bufio.Writer
+}
+
+// NewWriter escribe las partes fijas del archivo y deja abierta la hoja
+// sheetName para recibir filas.
+func NewWriter(w io.Writer, sheetName string) (*Writer, error) {
+	zw := zip.NewWriter(w)
+
+	for _, part := range staticParts {
+		if err := writePart(zw, part.name, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writePart(zw, "xl/workbook.xml", fmt.Sprintf(workbookTemplate, escapeXML(sheetName))); err != nil {
+		return nil, err
+	}
+
+	part, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := bufio.NewWriter(part)
+	sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	return &Writer{zw: zw, sheet: sheet}, nil
+}
+
+// WriteRow agrega una fila. Los enteros y flotantes se escriben como números,
+// las fechas en formato RFC 3339 y el resto como texto.
+func (w *Writer) WriteRow(values ...any) error {
+	w.sheet.WriteString("<row>")
+
+	for _, value := range values {
+		switch v := value.(type) {
+		case nil:
+			w.sheet.WriteString("<c/>")
+		case int:
+			w.writeNumber(strconv.Itoa(v))
+		case int64:
+			w.writeNumber(strconv.FormatInt(v, 10))
+		case float64:
+			w.writeNumber(strconv.FormatFloat(v, 'f', -1, 64))
+		case time.Time:
+			w.writeString(v.Format(time.RFC3339))
+		case string:
+			w.writeString(v)
+		default:
+			w.writeString(fmt.Sprint(v))
+		}
+	}
+
+	_, err := w.sheet.WriteString("</row>")
+	return err
+}
+
+// Close cierra la hoja y el archivo zip. No cierra el io.Writer subyacente.
+func (w *Writer) Close() error {
+	w.sheet.WriteString("</sheetData></worksheet>")
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+	return w.zw.Close()
+}
+
+func (w *Writer) writeNumber(value string) {
+	w.sheet.WriteString(`<c><v>` + value + `</v></c>`)
+}
+
+func (w *Writer) writeString(value string) {
+	w.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">` + escapeXML(value) + `</t></is></c>`)
+}
+
+func writePart(zw *zip.Writer, name, content string) error {
+	part, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(part, content)
+	return err
+}
+
+// escapeXML escapa el texto para incluirlo dentro de un elemento XML.
+func escapeXML(value string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(value))
+	return b.String()
+}